@@ -1,41 +1,264 @@
 package deque
 
-import "github.com/ahKevinXy/go-web-tools/common/container/list"
+import (
+	"errors"
 
+	"github.com/ahKevinXy/go-web-tools/common/math"
+)
+
+// ErrEmpty 队列为空
+// 空队列调用PopFront、PopBack会panic(ErrEmpty)
+var ErrEmpty = errors.New("deque: empty")
+
+const (
+	chunkShift = 6
+	chunkSize  = 1 << chunkShift // 每个块的元素数量
+	chunkMask  = chunkSize - 1
+	minChunks  = 4 // 块环的最小长度
+)
+
+// 固定大小的块
+type chunk[T any] [chunkSize]T
+
+// Deque 双端队列
+// 由固定大小的块组成的环，两端入队出队均摊O(1)，按下标访问O(1)
+// 每chunkSize个元素才分配一次，元素连续存放，比链表对缓存更友好
+// 元素减少时释放空块，块环使用率低于1/4时缩小
+// 空队列调用PopFront、PopBack会panic(ErrEmpty)，不确定是否为空时使用TryPopFront、TryPopBack
+// 非线程安全，请加锁
 type Deque[T any] struct {
-	l *list.List[T]
+	chunks []*chunk[T] // 块环，长度是2的幂
+	head   int         // 第一个块在环中的下标
+	used   int         // 已分配的块数量
+	off    int         // 第一个元素在第一个块中的下标
+	n      int         // 元素数量
+	spare  *chunk[T]   // 释放的块保留一个，避免在块边界反复入队出队时频繁分配
 }
 
 func New[T any]() *Deque[T] {
-	return &Deque[T]{l: list.New[T]()}
+	return &Deque[T]{}
 }
 
 // PushFront 从队头入队
 func (d *Deque[T]) PushFront(elem T) {
-	d.l.PushFront(elem)
+	if d.off == 0 {
+		// 第一个块已满，在前面加一个块
+		d.reserve()
+		d.head = (d.head - 1) & (len(d.chunks) - 1)
+		d.chunks[d.head] = d.newChunk()
+		d.used++
+		d.off = chunkSize
+	}
+	d.off--
+	d.chunks[d.head][d.off] = elem
+	d.n++
 }
 
 // PushBack 从队尾入队
 func (d *Deque[T]) PushBack(elem T) {
-	d.l.PushBack(elem)
+	p := d.off + d.n
+	if p>>chunkShift == d.used {
+		// 最后一个块已满，在后面加一个块
+		d.reserve()
+		d.chunks[(d.head+d.used)&(len(d.chunks)-1)] = d.newChunk()
+		d.used++
+	}
+	d.chunk(p)[p&chunkMask] = elem
+	d.n++
 }
 
 // PopFront 从队头出队
+// 队列为空会panic(ErrEmpty)
 func (d *Deque[T]) PopFront() T {
-	return d.l.RemoveFront()
+	if d.n == 0 {
+		panic(ErrEmpty)
+	}
+	var zero T
+	c := d.chunks[d.head]
+	elem := c[d.off]
+	c[d.off] = zero
+	d.off++
+	d.n--
+	if d.off == chunkSize {
+		// 第一个块已经取空
+		d.chunks[d.head] = nil
+		d.freeChunk(c)
+		d.head = (d.head + 1) & (len(d.chunks) - 1)
+		d.used--
+		d.off = 0
+		d.shrink()
+	}
+	return elem
 }
 
 // PopBack 从队尾出队
+// 队列为空会panic(ErrEmpty)
 func (d *Deque[T]) PopBack() T {
-	return d.l.RemoveBack()
+	if d.n == 0 {
+		panic(ErrEmpty)
+	}
+	var zero T
+	p := d.off + d.n - 1
+	c := d.chunk(p)
+	elem := c[p&chunkMask]
+	c[p&chunkMask] = zero
+	d.n--
+	if p&chunkMask == 0 {
+		// 最后一个块已经取空
+		d.chunks[(d.head+d.used-1)&(len(d.chunks)-1)] = nil
+		d.freeChunk(c)
+		d.used--
+		d.shrink()
+	}
+	return elem
+}
+
+// TryPopFront 从队头出队
+// 队列为空时ok为false
+func (d *Deque[T]) TryPopFront() (elem T, ok bool) {
+	if d.n == 0 {
+		return elem, false
+	}
+	return d.PopFront(), true
+}
+
+// TryPopBack 从队尾出队
+// 队列为空时ok为false
+func (d *Deque[T]) TryPopBack() (elem T, ok bool) {
+	if d.n == 0 {
+		return elem, false
+	}
+	return d.PopBack(), true
+}
+
+// PeekFront 队头元素
+// 队列为空时ok为false
+func (d *Deque[T]) PeekFront() (elem T, ok bool) {
+	if d.n == 0 {
+		return elem, false
+	}
+	return d.chunks[d.head][d.off], true
+}
+
+// PeekBack 队尾元素
+// 队列为空时ok为false
+func (d *Deque[T]) PeekBack() (elem T, ok bool) {
+	if d.n == 0 {
+		return elem, false
+	}
+	return d.At(d.n - 1), true
+}
+
+// Range 从队头到队尾遍历
+// fn返回false时停止遍历，遍历期间不能修改队列
+func (d *Deque[T]) Range(fn func(elem T) bool) {
+	for i := 0; i < d.n; i++ {
+		p := d.off + i
+		if !fn(d.chunk(p)[p&chunkMask]) {
+			return
+		}
+	}
+}
+
+// Clear 清空队列
+// 释放所有块
+func (d *Deque[T]) Clear() {
+	*d = Deque[T]{}
+}
+
+// At 第i个元素，队头为0
+// 下标越界会panic
+func (d *Deque[T]) At(i int) T {
+	d.checkIndex(i)
+	p := d.off + i
+	return d.chunk(p)[p&chunkMask]
+}
+
+// Set 修改第i个元素，队头为0
+// 下标越界会panic
+func (d *Deque[T]) Set(i int, elem T) {
+	d.checkIndex(i)
+	p := d.off + i
+	d.chunk(p)[p&chunkMask] = elem
+}
+
+// Rotate 循环移动k步
+// k大于0时把队尾的k个元素移到队头，小于0时把队头的-k个元素移到队尾
+// 按照较短的方向移动，最多移动Len()/2个元素
+func (d *Deque[T]) Rotate(k int) {
+	if d.n <= 1 {
+		return
+	}
+	k %= d.n
+	if k < 0 {
+		k += d.n
+	}
+	if k > d.n/2 {
+		for i := d.n - k; i > 0; i-- {
+			d.PushBack(d.PopFront())
+		}
+		return
+	}
+	for ; k > 0; k-- {
+		d.PushFront(d.PopBack())
+	}
 }
 
 // Len 队列元素个数
 func (d *Deque[T]) Len() int {
-	return d.l.Len()
+	return d.n
 }
 
 // Empty 队列是否为空
 func (d *Deque[T]) Empty() bool {
-	return d.l.Empty()
+	return d.n == 0
+}
+
+// 相对第一个块起点的位置p所在的块
+func (d *Deque[T]) chunk(p int) *chunk[T] {
+	return d.chunks[(d.head+p>>chunkShift)&(len(d.chunks)-1)]
+}
+
+func (d *Deque[T]) checkIndex(i int) {
+	if i < 0 || i >= d.n {
+		panic("index out of range")
+	}
+}
+
+// 保证块环至少还能再放一个块
+func (d *Deque[T]) reserve() {
+	if d.used < len(d.chunks) {
+		return
+	}
+	d.resize(math.Max(2*len(d.chunks), minChunks))
+}
+
+// 块环使用率低于1/4时缩小一半
+func (d *Deque[T]) shrink() {
+	if len(d.chunks) > minChunks && d.used < len(d.chunks)/4 {
+		d.resize(len(d.chunks) / 2)
+	}
+}
+
+// 重新分配块环，已分配的块从下标0开始连续存放
+func (d *Deque[T]) resize(size int) {
+	chunks := make([]*chunk[T], size)
+	for i := 0; i < d.used; i++ {
+		chunks[i] = d.chunks[(d.head+i)&(len(d.chunks)-1)]
+	}
+	d.chunks = chunks
+	d.head = 0
+}
+
+func (d *Deque[T]) newChunk() *chunk[T] {
+	if c := d.spare; c != nil {
+		d.spare = nil
+		return c
+	}
+	return new(chunk[T])
+}
+
+// 出队时已经清零，可以直接复用
+func (d *Deque[T]) freeChunk(c *chunk[T]) {
+	d.spare = c
 }
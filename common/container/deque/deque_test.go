@@ -0,0 +1,115 @@
+package deque
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	d := New[int]()
+	var model []int
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		// 前半段入队多，后半段出队多，覆盖扩容和缩小
+		push := r.Intn(100) < 60
+		if i >= 50000 {
+			push = r.Intn(100) < 40
+		}
+		switch {
+		case push && r.Intn(2) == 0:
+			d.PushFront(i)
+			model = append([]int{i}, model...)
+		case push:
+			d.PushBack(i)
+			model = append(model, i)
+		case len(model) == 0:
+			continue
+		case r.Intn(2) == 0:
+			if got := d.PopFront(); got != model[0] {
+				t.Fatalf("PopFront() = %v, want %v", got, model[0])
+			}
+			model = model[1:]
+		default:
+			if got := d.PopBack(); got != model[len(model)-1] {
+				t.Fatalf("PopBack() = %v, want %v", got, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		}
+		if d.Len() != len(model) {
+			t.Fatalf("Len() = %v, want %v", d.Len(), len(model))
+		}
+		if len(model) > 0 {
+			j := r.Intn(len(model))
+			if got := d.At(j); got != model[j] {
+				t.Fatalf("At(%v) = %v, want %v", j, got, model[j])
+			}
+		}
+	}
+	for d.Len() > 0 {
+		d.PopBack()
+	}
+	if len(d.chunks) > minChunks || d.used > 1 {
+		t.Errorf("chunks = %v %v, want shrunk", len(d.chunks), d.used)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		k    int
+		want []int
+	}{
+		{0, []int{0, 1, 2, 3, 4}},
+		{2, []int{3, 4, 0, 1, 2}},
+		{4, []int{1, 2, 3, 4, 0}},
+		{-1, []int{1, 2, 3, 4, 0}},
+		{-7, []int{2, 3, 4, 0, 1}},
+	}
+	for _, tt := range tests {
+		d := New[int]()
+		for i := 0; i < 5; i++ {
+			d.PushBack(i)
+		}
+		d.Rotate(tt.k)
+		for i, want := range tt.want {
+			if got := d.At(i); got != want {
+				t.Errorf("Rotate(%v) At(%v) = %v, want %v", tt.k, i, got, want)
+			}
+		}
+	}
+	d := New[string]()
+	d.PushBack("a")
+	d.Set(0, "b")
+	if got := d.PopFront(); got != "b" {
+		t.Errorf("Set() = %v, want %v", got, "b")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	d := New[int]()
+	if _, ok := d.TryPopFront(); ok {
+		t.Errorf("TryPopFront() ok = %v, want %v", ok, false)
+	}
+	if _, ok := d.PeekBack(); ok {
+		t.Errorf("PeekBack() ok = %v, want %v", ok, false)
+	}
+	d.PushBack(1)
+	d.PushBack(2)
+	if v, ok := d.PeekFront(); v != 1 || !ok {
+		t.Errorf("PeekFront() = %v %v, want %v", v, ok, 1)
+	}
+	var sum int
+	d.Range(func(elem int) bool {
+		sum += elem
+		return true
+	})
+	if sum != 3 {
+		t.Errorf("Range() sum = %v, want %v", sum, 3)
+	}
+	d.Clear()
+	defer func() {
+		if err := recover(); err != ErrEmpty {
+			t.Errorf("PopBack() panic = %v, want %v", err, ErrEmpty)
+		}
+	}()
+	d.PopBack()
+}
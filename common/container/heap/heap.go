@@ -45,6 +45,11 @@ func (h *Heap[T]) Empty() bool {
 	return h.Len() == 0
 }
 
+// 清空堆
+func (h *Heap[T]) Clear() {
+	h.h = nil
+}
+
 // Remove removes and returns the element at index i from the heap.
 // The complexity is O(log n) where n = h.Len().
 func (h *Heap[T]) Remove(i int) T {
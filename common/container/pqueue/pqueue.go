@@ -1,8 +1,18 @@
 package pqueue
 
-import "github.com/ahKevinXy/go-web-tools/common/container/heap"
+import (
+	"errors"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/heap"
+)
+
+// ErrEmpty 队列为空
+// 空队列调用Pop、Peek会panic(ErrEmpty)
+var ErrEmpty = errors.New("pqueue: empty")
 
 // PriorityQueue 优先队列
+// 空队列调用Pop、Peek会panic(ErrEmpty)，不确定是否为空时使用TryPop、TryPeek
+// 非线程安全，请加锁
 type PriorityQueue[T any] struct {
 	h *heap.Heap[T]
 }
@@ -19,15 +29,46 @@ func (p *PriorityQueue[T]) Push(elem T) {
 }
 
 // Pop 出队
+// 队列为空会panic(ErrEmpty)
 func (p *PriorityQueue[T]) Pop() T {
+	if p.Empty() {
+		panic(ErrEmpty)
+	}
 	return p.h.Pop()
 }
 
+// TryPop 出队
+// 队列为空时ok为false
+func (p *PriorityQueue[T]) TryPop() (elem T, ok bool) {
+	if p.Empty() {
+		return elem, false
+	}
+	return p.h.Pop(), true
+}
+
 // Peek 队头元素
+// 队列为空会panic(ErrEmpty)
 func (p *PriorityQueue[T]) Peek() T {
+	if p.Empty() {
+		panic(ErrEmpty)
+	}
 	return p.h.Peek()
 }
 
+// TryPeek 队头元素
+// 队列为空时ok为false
+func (p *PriorityQueue[T]) TryPeek() (elem T, ok bool) {
+	if p.Empty() {
+		return elem, false
+	}
+	return p.h.Peek(), true
+}
+
+// Clear 清空队列
+func (p *PriorityQueue[T]) Clear() {
+	p.h.Clear()
+}
+
 // Len 队列元素个数
 func (p *PriorityQueue[T]) Len() int {
 	return p.h.Len()
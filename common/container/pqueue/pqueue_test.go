@@ -0,0 +1,54 @@
+package pqueue
+
+import "testing"
+
+func TestPriorityQueue(t *testing.T) {
+	p := New([]int{5, 1, 4}, func(e1, e2 int) bool { return e1 < e2 })
+	p.Push(3)
+	p.Push(2)
+	if elem, ok := p.TryPeek(); elem != 1 || !ok {
+		t.Errorf("TryPeek() = %v %v, want %v true", elem, ok, 1)
+	}
+	for want := 1; want <= 4; want++ {
+		if elem, ok := p.TryPop(); elem != want || !ok {
+			t.Fatalf("TryPop() = %v %v, want %v true", elem, ok, want)
+		}
+	}
+	if elem := p.Pop(); elem != 5 {
+		t.Errorf("Pop() = %v, want %v", elem, 5)
+	}
+	if _, ok := p.TryPop(); ok {
+		t.Errorf("TryPop() ok = true, want false")
+	}
+	if _, ok := p.TryPeek(); ok {
+		t.Errorf("TryPeek() ok = true, want false")
+	}
+
+	p.Push(7)
+	p.Push(6)
+	p.Clear()
+	if !p.Empty() || p.Len() != 0 {
+		t.Errorf("Empty() = %v, Len() = %v after Clear(), want true, 0", p.Empty(), p.Len())
+	}
+	p.Push(8)
+	if elem := p.Peek(); elem != 8 {
+		t.Errorf("Peek() after Clear() = %v, want %v", elem, 8)
+	}
+}
+
+func TestPriorityQueueEmptyPanic(t *testing.T) {
+	p := New(nil, func(e1, e2 int) bool { return e1 < e2 })
+	for name, fn := range map[string]func(){
+		"Pop":  func() { p.Pop() },
+		"Peek": func() { p.Peek() },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err != ErrEmpty {
+					t.Errorf("%v() panic = %v, want %v", name, err, ErrEmpty)
+				}
+			}()
+			fn()
+		}()
+	}
+}
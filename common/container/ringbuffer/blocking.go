@@ -0,0 +1,211 @@
+package ringbuffer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/fix"
+	"github.com/ahKevinXy/go-web-tools/common/math"
+)
+
+// BlockingQueue 有界阻塞队列
+// 队列满时Put阻塞，队列空时Take阻塞，可以被多个生产者和消费者并发使用
+// 关闭后不能再写入，已有的元素仍然可以读出，读空后返回ErrClosed
+type BlockingQueue[T any] struct {
+	mu       sync.Mutex
+	ring     *fix.Ring[T]
+	notEmpty chan struct{} // 有等待读的goroutine时创建，写入后关闭以唤醒
+	notFull  chan struct{} // 有等待写的goroutine时创建，读出后关闭以唤醒
+	closed   bool
+}
+
+// NewBlockingQueue 创建
+// size 队列容量
+func NewBlockingQueue[T any](size int) *BlockingQueue[T] {
+	if size <= 0 {
+		panic("size must be greater than 0")
+	}
+	return &BlockingQueue[T]{ring: fix.New[T](uint64(size))}
+}
+
+// Put 写入队尾
+// 队列满时阻塞，直到有空位、ctx结束或队列关闭
+func (q *BlockingQueue[T]) Put(ctx context.Context, v T) error {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return ErrClosed
+		}
+		if !q.ring.Full() {
+			q.ring.Push(v)
+			signal(&q.notEmpty)
+			q.mu.Unlock()
+			return nil
+		}
+		wait := waiter(&q.notFull)
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Take 读取队头
+// 队列空时阻塞，直到有元素、ctx结束或队列关闭
+func (q *BlockingQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		if !q.ring.Empty() {
+			v := q.ring.Pop()
+			signal(&q.notFull)
+			q.mu.Unlock()
+			return v, nil
+		}
+		if q.closed {
+			q.mu.Unlock()
+			var zero T
+			return zero, ErrClosed
+		}
+		wait := waiter(&q.notEmpty)
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Offer 写入队尾
+// 队列满时最多等待timeout，超时返回ErrTimeout；timeout不大于0时不等待
+func (q *BlockingQueue[T]) Offer(v T, timeout time.Duration) error {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+	err := q.Put(ctx, v)
+	if err != nil && err == ctx.Err() {
+		return ErrTimeout
+	}
+	return err
+}
+
+// Poll 读取队头
+// 队列空时最多等待timeout，超时返回ErrTimeout；timeout不大于0时不等待
+func (q *BlockingQueue[T]) Poll(timeout time.Duration) (T, error) {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+	v, err := q.Take(ctx)
+	if err != nil && err == ctx.Err() {
+		return v, ErrTimeout
+	}
+	return v, err
+}
+
+// DrainTo 不等待地读出最多len(dst)个元素到dst里
+// 返回读出的数量
+func (q *BlockingQueue[T]) DrainTo(dst []T) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := math.Min(len(dst), int(q.ring.Len()))
+	q.ring.MPopCopy(dst[:n])
+	if n > 0 {
+		signal(&q.notFull)
+	}
+	return n
+}
+
+// TakeBatch 批量读取
+// 队列空时阻塞直到至少有一个元素，然后读出最多len(dst)个元素到dst里，返回读出的数量
+func (q *BlockingQueue[T]) TakeBatch(ctx context.Context, dst []T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	v, err := q.Take(ctx)
+	if err != nil {
+		return 0, err
+	}
+	dst[0] = v
+	return 1 + q.DrainTo(dst[1:]), nil
+}
+
+// Close 关闭队列
+// 唤醒所有等待的goroutine；重复关闭没有影响
+func (q *BlockingQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	signal(&q.notEmpty)
+	signal(&q.notFull)
+}
+
+// Closed 是否已关闭
+func (q *BlockingQueue[T]) Closed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// Len 元素数量
+func (q *BlockingQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int(q.ring.Len())
+}
+
+// Cap 容量
+func (q *BlockingQueue[T]) Cap() int {
+	return int(q.ring.Cap())
+}
+
+// 获取等待的channel，需要持有锁
+func waiter(ch *chan struct{}) <-chan struct{} {
+	if *ch == nil {
+		*ch = make(chan struct{})
+	}
+	return *ch
+}
+
+// 唤醒所有等待者，需要持有锁
+// 被唤醒的goroutine重新检查条件，没有抢到的继续等待
+func signal(ch *chan struct{}) {
+	if *ch != nil {
+		close(*ch)
+		*ch = nil
+	}
+}
+
+// 等待ch被关闭，deadline为零值时不超时
+func wait(ch <-chan struct{}, deadline time.Time) error {
+	if deadline.IsZero() {
+		<-ch
+		return nil
+	}
+	d := time.Until(deadline)
+	if d <= 0 {
+		return ErrTimeout
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ch:
+		return nil
+	case <-t.C:
+		return ErrTimeout
+	}
+}
+
+// timeout不大于0时返回已经结束的context
+func timeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx, cancel
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
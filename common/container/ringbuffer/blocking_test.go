@@ -0,0 +1,91 @@
+package ringbuffer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockingQueue(t *testing.T) {
+	q := NewBlockingQueue[int](4)
+	const producers, perProducer = 4, 1000
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= perProducer; i++ {
+				if err := q.Put(context.Background(), i); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		q.Close()
+	}()
+
+	results := make(chan int)
+	for c := 0; c < 3; c++ {
+		go func() {
+			sum := 0
+			buf := make([]int, 8)
+			for {
+				n, err := q.TakeBatch(context.Background(), buf)
+				if err == ErrClosed {
+					break
+				}
+				for _, v := range buf[:n] {
+					sum += v
+				}
+			}
+			results <- sum
+		}()
+	}
+	sum := 0
+	for c := 0; c < 3; c++ {
+		sum += <-results
+	}
+	if want := producers * perProducer * (perProducer + 1) / 2; sum != want {
+		t.Errorf("sum = %v, want %v", sum, want)
+	}
+}
+
+func TestBlockingQueueTimeout(t *testing.T) {
+	q := NewBlockingQueue[int](1)
+	if _, err := q.Poll(0); err != ErrTimeout {
+		t.Errorf("Poll() = %v, want %v", err, ErrTimeout)
+	}
+	if err := q.Offer(1, 0); err != nil {
+		t.Errorf("Offer() = %v, want %v", err, nil)
+	}
+	if err := q.Offer(2, 10*time.Millisecond); err != ErrTimeout {
+		t.Errorf("Offer() = %v, want %v", err, ErrTimeout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.Put(ctx, 2); err != context.Canceled {
+		t.Errorf("Put() = %v, want %v", err, context.Canceled)
+	}
+
+	// 关闭唤醒等待的写入，已有的元素仍然可以读出
+	done := make(chan error)
+	go func() {
+		done <- q.Put(context.Background(), 2)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	if err := <-done; err != ErrClosed {
+		t.Errorf("Put() = %v, want %v", err, ErrClosed)
+	}
+	if v, err := q.Take(context.Background()); v != 1 || err != nil {
+		t.Errorf("Take() = %v %v, want %v", v, err, 1)
+	}
+	if _, err := q.Take(context.Background()); err != ErrClosed {
+		t.Errorf("Take() = %v, want %v", err, ErrClosed)
+	}
+}
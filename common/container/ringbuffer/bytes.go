@@ -0,0 +1,360 @@
+package ringbuffer
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	_ io.Reader     = (*Bytes)(nil)
+	_ io.Writer     = (*Bytes)(nil)
+	_ io.ByteReader = (*Bytes)(nil)
+	_ io.ByteWriter = (*Bytes)(nil)
+	_ io.WriterTo   = (*Bytes)(nil)
+	_ io.ReaderFrom = (*Bytes)(nil)
+)
+
+// Bytes 字节环形缓冲区
+// 默认非阻塞：没有数据时读取返回io.EOF，空间不足时写入已经写入的部分并返回ErrFull
+// SetBlocking(true)后读写会等待，直到有数据或空间、缓冲区关闭或超过deadline
+// 所有方法都可以并发调用；WriteTo和ReadFrom直接在内部空间上读写，同一时间只能有一个读取者和一个写入者
+// Reset会等待正在进行的WriteTo、ReadFrom读写完内部空间，不能在它们的io.Writer、io.Reader里调用Reset
+type Bytes struct {
+	mu            sync.Mutex
+	in            uint64 // 写索引
+	out           uint64 // 读索引
+	size          uint64 // 长度
+	data          []byte // 数据
+	blocking      bool
+	closed        bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+	readable      chan struct{} // 有等待读的goroutine时创建，写入后关闭以唤醒
+	writable      chan struct{} // 有等待写的goroutine时创建，读出后关闭以唤醒
+	reading       bool          // WriteTo正在不持有锁地读内部空间
+	writing       bool          // ReadFrom正在不持有锁地写内部空间
+	idle          chan struct{} // Reset等待reading和writing结束时创建
+}
+
+// NewBytes 创建
+// size 缓冲区大小
+func NewBytes(size int) *Bytes {
+	if size <= 0 {
+		panic("size must be greater than 0")
+	}
+	return &Bytes{size: uint64(size), data: make([]byte, size)}
+}
+
+// Read 实现io.Reader
+func (b *Bytes) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := b.waitData(); err != nil {
+		return 0, err
+	}
+	defer b.mu.Unlock()
+	first, second := b.segments(b.out, b.length())
+	n := copy(p, first)
+	n += copy(p[n:], second)
+	b.consume(uint64(n))
+	return n, nil
+}
+
+// ReadByte 实现io.ByteReader
+func (b *Bytes) ReadByte() (byte, error) {
+	if err := b.waitData(); err != nil {
+		return 0, err
+	}
+	defer b.mu.Unlock()
+	c := b.data[b.out%b.size]
+	b.consume(1)
+	return c, nil
+}
+
+// Write 实现io.Writer
+// 非阻塞模式下空间不足时返回已写入的长度和ErrFull
+func (b *Bytes) Write(p []byte) (int, error) {
+	var total int
+	for len(p) > 0 {
+		if err := b.waitSpace(); err != nil {
+			return total, err
+		}
+		first, second := b.segments(b.in, b.size-b.length())
+		n := copy(first, p)
+		n += copy(second, p[n:])
+		b.produce(uint64(n))
+		b.mu.Unlock()
+		total += n
+		p = p[n:]
+	}
+	return total, nil
+}
+
+// WriteByte 实现io.ByteWriter
+func (b *Bytes) WriteByte(c byte) error {
+	if err := b.waitSpace(); err != nil {
+		return err
+	}
+	defer b.mu.Unlock()
+	b.data[b.in%b.size] = c
+	b.produce(1)
+	return nil
+}
+
+// WriteTo 实现io.WriterTo
+// 把数据直接从内部空间写入w，非阻塞模式下读空时返回，阻塞模式下直到缓冲区关闭
+func (b *Bytes) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for {
+		if err := b.waitData(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return total, err
+		}
+		seg, _ := b.segments(b.out, b.length())
+		b.reading = true
+		b.mu.Unlock()
+
+		n, err := w.Write(seg)
+		if n < 0 || n > len(seg) {
+			n = 0
+			if err == nil {
+				err = errInvalidWrite
+			}
+		}
+		b.mu.Lock()
+		b.reading = false
+		b.consume(uint64(n))
+		signal(&b.idle)
+		b.mu.Unlock()
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if n < len(seg) {
+			return total, io.ErrShortWrite
+		}
+	}
+}
+
+// ReadFrom 实现io.ReaderFrom
+// 从r直接读入内部空间，直到r返回io.EOF；非阻塞模式下空间不足时返回ErrFull
+func (b *Bytes) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		if err := b.waitSpace(); err != nil {
+			return total, err
+		}
+		seg, _ := b.segments(b.in, b.size-b.length())
+		b.writing = true
+		b.mu.Unlock()
+
+		n, err := r.Read(seg)
+		if n < 0 || n > len(seg) {
+			n = 0
+			if err == nil || err == io.EOF {
+				err = errInvalidRead
+			}
+		}
+		b.mu.Lock()
+		b.writing = false
+		b.produce(uint64(n))
+		signal(&b.idle)
+		b.mu.Unlock()
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// Peek 查看最多n个字节但不读出
+// 数据可能跨越缓冲区末尾，分成两段返回，不复制；返回的切片在读出这些数据或Reset之前有效
+func (b *Bytes) Peek(n int) (first, second []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	length := b.length()
+	if uint64(n) < length {
+		length = uint64(n)
+	}
+	return b.segments(b.out, length)
+}
+
+// Discard 丢弃最多n个字节
+// 返回丢弃的长度，非阻塞模式下数据不足时返回io.EOF
+func (b *Bytes) Discard(n int) (int, error) {
+	var total int
+	for total < n {
+		if err := b.waitData(); err != nil {
+			return total, err
+		}
+		d := b.length()
+		if rest := uint64(n - total); rest < d {
+			d = rest
+		}
+		b.consume(d)
+		b.mu.Unlock()
+		total += int(d)
+	}
+	return total, nil
+}
+
+// SetBlocking 设置是否阻塞读写
+func (b *Bytes) SetBlocking(blocking bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blocking = blocking
+	b.wakeAll()
+}
+
+// SetReadDeadline 设置阻塞读的截止时间，超过后返回ErrTimeout
+// 零值表示不超时
+func (b *Bytes) SetReadDeadline(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.readDeadline = t
+	b.wakeAll()
+}
+
+// SetWriteDeadline 设置阻塞写的截止时间，超过后返回ErrTimeout
+// 零值表示不超时
+func (b *Bytes) SetWriteDeadline(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.writeDeadline = t
+	b.wakeAll()
+}
+
+// Close 关闭缓冲区
+// 之后写入返回ErrClosed，已有的数据仍然可以读出，读空后返回io.EOF
+func (b *Bytes) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.wakeAll()
+	return nil
+}
+
+// Reset 清空数据
+// 等待正在进行的WriteTo、ReadFrom读写完内部空间后再清空，避免它们之后按照旧的位置读出、写入
+func (b *Bytes) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.reading || b.writing {
+		ch := waiter(&b.idle)
+		b.mu.Unlock()
+		<-ch
+		b.mu.Lock()
+	}
+	b.in, b.out = 0, 0
+	signal(&b.writable)
+}
+
+// Len 可读的长度
+func (b *Bytes) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.length())
+}
+
+// Free 可写的长度
+func (b *Bytes) Free() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.size - b.length())
+}
+
+// Cap 总长度
+func (b *Bytes) Cap() int {
+	return int(b.size)
+}
+
+// 可读的长度，需要持有锁
+func (b *Bytes) length() uint64 {
+	return b.in - b.out
+}
+
+// 从索引pos开始长度为n的两段空间
+func (b *Bytes) segments(pos, n uint64) (first, second []byte) {
+	start := pos % b.size
+	end := start + n
+	if end <= b.size {
+		return b.data[start:end], nil
+	}
+	return b.data[start:], b.data[:end-b.size]
+}
+
+// 读出n个字节，需要持有锁
+func (b *Bytes) consume(n uint64) {
+	if n == 0 {
+		return
+	}
+	b.out += n
+	signal(&b.writable)
+}
+
+// 写入n个字节，需要持有锁
+func (b *Bytes) produce(n uint64) {
+	if n == 0 {
+		return
+	}
+	b.in += n
+	signal(&b.readable)
+}
+
+// 等待有数据可读，成功时持有锁返回，失败时已经释放锁
+func (b *Bytes) waitData() error {
+	b.mu.Lock()
+	for b.length() == 0 {
+		if b.closed || !b.blocking {
+			b.mu.Unlock()
+			return io.EOF
+		}
+		ch, deadline := waiter(&b.readable), b.readDeadline
+		b.mu.Unlock()
+		if err := wait(ch, deadline); err != nil {
+			return err
+		}
+		b.mu.Lock()
+	}
+	return nil
+}
+
+// 等待有空间可写，成功时持有锁返回，失败时已经释放锁
+func (b *Bytes) waitSpace() error {
+	b.mu.Lock()
+	for {
+		if b.closed {
+			b.mu.Unlock()
+			return ErrClosed
+		}
+		if b.length() < b.size {
+			return nil
+		}
+		if !b.blocking {
+			b.mu.Unlock()
+			return ErrFull
+		}
+		ch, deadline := waiter(&b.writable), b.writeDeadline
+		b.mu.Unlock()
+		if err := wait(ch, deadline); err != nil {
+			return err
+		}
+		b.mu.Lock()
+	}
+}
+
+// 唤醒所有等待者重新检查条件，需要持有锁
+func (b *Bytes) wakeAll() {
+	signal(&b.readable)
+	signal(&b.writable)
+}
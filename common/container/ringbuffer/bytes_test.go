@@ -0,0 +1,153 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	b := NewBytes(8)
+	if n, err := b.Write([]byte("hello world")); n != 8 || err != ErrFull {
+		t.Errorf("Write() = %v %v, want %v %v", n, err, 8, ErrFull)
+	}
+	if n, err := b.Discard(6); n != 6 || err != nil {
+		t.Errorf("Discard() = %v %v, want %v", n, err, 6)
+	}
+	b.Write([]byte("rld"))
+	// "wo"在末尾，"rld"绕回开头
+	first, second := b.Peek(10)
+	if string(first) != "wo" || string(second) != "rld" {
+		t.Errorf("Peek() = %q %q, want %q %q", first, second, "wo", "rld")
+	}
+	if c, err := b.ReadByte(); c != 'w' || err != nil {
+		t.Errorf("ReadByte() = %q %v, want %q", c, err, 'w')
+	}
+	var out bytes.Buffer
+	if n, err := b.WriteTo(&out); n != 4 || err != nil || out.String() != "orld" {
+		t.Errorf("WriteTo() = %v %v %q, want %v %q", n, err, out.String(), 4, "orld")
+	}
+	if _, err := b.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Read() on empty = %v, want %v", err, io.EOF)
+	}
+	if n, err := b.ReadFrom(strings.NewReader("abc")); n != 3 || err != nil || b.Len() != 3 {
+		t.Errorf("ReadFrom() = %v %v, want %v", n, err, 3)
+	}
+}
+
+func TestBytesBlocking(t *testing.T) {
+	b := NewBytes(4)
+	b.SetBlocking(true)
+	src := strings.Repeat("0123456789", 100)
+	go func() {
+		io.Copy(b, strings.NewReader(src))
+		b.Close()
+	}()
+	got, err := io.ReadAll(b)
+	if err != nil || string(got) != src {
+		t.Errorf("ReadAll() = %v %v", len(got), err)
+	}
+	if _, err := b.Write([]byte("x")); err != ErrClosed {
+		t.Errorf("Write() after Close = %v, want %v", err, ErrClosed)
+	}
+
+	b = NewBytes(4)
+	b.SetBlocking(true)
+	b.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := b.Read(make([]byte, 1)); err != ErrTimeout {
+		t.Errorf("Read() = %v, want %v", err, ErrTimeout)
+	}
+}
+
+// 在Write、Read里停住的io.Writer、io.Reader
+type pausedIO struct {
+	entered chan struct{}
+	release chan struct{}
+	n       int // 返回的长度，小于0时使用len(p)
+}
+
+func (p *pausedIO) Write(b []byte) (int, error) {
+	close(p.entered)
+	<-p.release
+	if p.n < 0 {
+		return len(b), nil
+	}
+	return p.n, nil
+}
+
+func (p *pausedIO) Read(b []byte) (int, error) {
+	close(p.entered)
+	<-p.release
+	copy(b, "abcdefgh")
+	if p.n < 0 {
+		return len(b), io.EOF
+	}
+	return p.n, io.EOF
+}
+
+func TestBytesResetDuringIO(t *testing.T) {
+	for _, name := range []string{"WriteTo", "ReadFrom"} {
+		t.Run(name, func(t *testing.T) {
+			b := NewBytes(8)
+			b.Write([]byte("0123"))
+			p := &pausedIO{entered: make(chan struct{}), release: make(chan struct{}), n: -1}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				if name == "WriteTo" {
+					b.WriteTo(p)
+				} else {
+					b.ReadFrom(p)
+				}
+			}()
+			<-p.entered
+
+			reset := make(chan struct{})
+			go func() {
+				b.Reset()
+				close(reset)
+			}()
+			select {
+			case <-reset:
+				t.Fatalf("Reset() returned while %v was using the buffer", name)
+			case <-time.After(20 * time.Millisecond):
+			}
+			close(p.release)
+			<-reset
+			<-done
+
+			// Reset在读写完成之后清空，位置没有错乱
+			if b.Len() != 0 || b.Free() != b.Cap() {
+				t.Fatalf("Len() = %v, Free() = %v, want 0, %v", b.Len(), b.Free(), b.Cap())
+			}
+			b.Write([]byte("xyz"))
+			if got, _ := io.ReadAll(b); string(got) != "xyz" {
+				t.Errorf("ReadAll() = %q, want %q", got, "xyz")
+			}
+		})
+	}
+}
+
+func TestBytesInvalidIOResult(t *testing.T) {
+	b := NewBytes(8)
+	b.Write([]byte("0123"))
+	p := &pausedIO{entered: make(chan struct{}), release: make(chan struct{}), n: 100}
+	close(p.release)
+	if n, err := b.WriteTo(p); n != 0 || err != errInvalidWrite {
+		t.Errorf("WriteTo() = %v %v, want %v %v", n, err, 0, errInvalidWrite)
+	}
+	if b.Len() != 4 {
+		t.Errorf("Len() = %v, want %v", b.Len(), 4)
+	}
+
+	p = &pausedIO{entered: make(chan struct{}), release: make(chan struct{}), n: 100}
+	close(p.release)
+	if n, err := b.ReadFrom(p); n != 0 || err != errInvalidRead {
+		t.Errorf("ReadFrom() = %v %v, want %v %v", n, err, 0, errInvalidRead)
+	}
+	if b.Len() != 4 {
+		t.Errorf("Len() = %v, want %v", b.Len(), 4)
+	}
+}
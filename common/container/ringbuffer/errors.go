@@ -0,0 +1,23 @@
+package ringbuffer
+
+import (
+	"errors"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/internal/ringerr"
+)
+
+var (
+	// ErrClosed 已关闭
+	ErrClosed = errors.New("ringbuffer: closed")
+	// ErrTimeout 等待超时
+	ErrTimeout = errors.New("ringbuffer: timeout")
+	// ErrEmpty 队列为空，和fix、round、unbounded的ErrEmpty是同一个值
+	ErrEmpty = ringerr.ErrEmpty
+	// ErrFull 缓冲区已满，和fix、round的ErrFull是同一个值
+	ErrFull = ringerr.ErrFull
+
+	// io.Writer返回的长度不合法
+	errInvalidWrite = errors.New("ringbuffer: invalid write result")
+	// io.Reader返回的长度不合法
+	errInvalidRead = errors.New("ringbuffer: invalid read result")
+)
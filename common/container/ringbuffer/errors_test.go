@@ -0,0 +1,23 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/fix"
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/round"
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/unbounded"
+)
+
+func TestSharedErrors(t *testing.T) {
+	for _, err := range []error{fix.ErrEmpty, round.ErrEmpty, unbounded.ErrEmpty} {
+		if !errors.Is(err, ErrEmpty) {
+			t.Errorf("errors.Is(%v, ErrEmpty) = false, want true", err)
+		}
+	}
+	for _, err := range []error{fix.ErrFull, round.ErrFull} {
+		if !errors.Is(err, ErrFull) {
+			t.Errorf("errors.Is(%v, ErrFull) = false, want true", err)
+		}
+	}
+}
@@ -3,19 +3,31 @@ package fix
 import (
 	"math"
 
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/internal/ringerr"
 	mmath "github.com/ahKevinXy/go-web-tools/common/math"
 )
 
+// ErrEmpty 队列为空
+// 空队列调用Pop、Peek会panic(ErrEmpty)
+var ErrEmpty = ringerr.ErrEmpty
+
+// ErrFull 队列已满
+// 满队列调用Push会panic(ErrFull)
+var ErrFull = ringerr.ErrFull
+
 // MaxSize 最大长度
 const MaxSize = math.MaxInt64
 
 // Ring 固定长度
-// 单生产者和单消费者情况下是线程安全性的，但是不能用Reset()方法
+// 空队列调用Pop、Peek会panic(ErrEmpty)，满队列调用Push会panic(ErrFull)，不确定时使用TryPop、TryPeek、TryPush
+// 单生产者和单消费者情况下是线程安全性的，但是不能用Reset()方法，覆盖模式下也不是线程安全的
 type Ring[T any] struct {
-	in   uint64 // 写索引
-	out  uint64 // 读索引
-	size uint64 // 长度
-	data []T    // 数据
+	in        uint64 // 写索引
+	out       uint64 // 读索引
+	overwrite bool   // 满了之后写入是否覆盖最旧的元素
+	evicted   uint64 // 被覆盖的元素数量
+	size      uint64 // 长度
+	data      []T    // 数据
 }
 
 // New[T any]
@@ -37,10 +49,18 @@ func New[T any](size uint64) *Ring[T] {
 	}
 }
 
+// NewOverwrite 创建覆盖模式的环
+// 满了之后Push和MPush覆盖最旧的元素而不是panic或截断，适合保存最近N条记录
+func NewOverwrite[T any](size uint64) *Ring[T] {
+	r := New[T](size)
+	r.overwrite = true
+	return r
+}
+
 // 弹出队头元素
 func (r *Ring[T]) Pop() T {
 	if r.Empty() {
-		panic("ring emtpy")
+		panic(ErrEmpty)
 	}
 	out := r.out % r.size
 	r.out++
@@ -50,24 +70,69 @@ func (r *Ring[T]) Pop() T {
 // 队头元素
 func (r *Ring[T]) Peek() T {
 	if r.Empty() {
-		panic("ring emtpy")
+		panic(ErrEmpty)
 	}
 	return r.data[r.out%r.size]
 }
 
 // 插入元素到队尾
+// 覆盖模式下满了之后覆盖最旧的元素
 func (r *Ring[T]) Push(e T) {
 	if r.Full() {
-		panic("ring full")
+		if !r.overwrite {
+			panic(ErrFull)
+		}
+		r.out++
+		r.evicted++
 	}
 	in := r.in % r.size
 	r.in++
 	r.data[in] = e
 }
 
+// 弹出队头元素
+// 队列为空时ok为false
+func (r *Ring[T]) TryPop() (e T, ok bool) {
+	if r.Empty() {
+		return e, false
+	}
+	return r.Pop(), true
+}
+
+// 队头元素
+// 队列为空时ok为false
+func (r *Ring[T]) TryPeek() (e T, ok bool) {
+	if r.Empty() {
+		return e, false
+	}
+	return r.Peek(), true
+}
+
+// 插入元素到队尾
+// 队列已满时返回false，覆盖模式下总是返回true
+func (r *Ring[T]) TryPush(e T) bool {
+	if r.Full() && !r.overwrite {
+		return false
+	}
+	r.Push(e)
+	return true
+}
+
 // 写入队尾
+// 覆盖模式下空间不足时覆盖最旧的元素，否则只写入剩余长度
 func (r *Ring[T]) MPush(elems ...T) {
 	size := uint64(len(elems))
+	if r.overwrite && size > r.Avail() {
+		if size > r.size {
+			// 只有最后size个元素能留下
+			r.evicted += size - r.size
+			elems = elems[size-r.size:]
+			size = r.size
+		}
+		drop := size - r.Avail()
+		r.out += drop
+		r.evicted += drop
+	}
 	// 不能大于剩余长度
 	if size > r.Avail() {
 		size = r.Avail()
@@ -107,10 +172,25 @@ func (r *Ring[T]) MPopCopy(dst []T) {
 	r.out += uint64(copied)
 }
 
-// 重置读写指针
+// 从旧到新复制所有元素，不读出
+func (r *Ring[T]) Snapshot() []T {
+	elems := make([]T, r.Len())
+	out := r.out % r.size
+	copied := copy(elems, r.data[out:])
+	copy(elems[copied:], r.data)
+	return elems
+}
+
+// 被覆盖的元素数量
+func (r *Ring[T]) Evicted() uint64 {
+	return r.evicted
+}
+
+// 重置读写指针和覆盖计数
 func (r *Ring[T]) Reset() {
 	r.in = 0
 	r.out = 0
+	r.evicted = 0
 	r.data = make([]T, r.size)
 }
 
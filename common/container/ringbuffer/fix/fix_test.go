@@ -0,0 +1,30 @@
+package fix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOverwrite(t *testing.T) {
+	r := NewOverwrite[int](3)
+	for i := 1; i <= 5; i++ {
+		r.Push(i)
+	}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{3, 4, 5}) || r.Evicted() != 2 {
+		t.Errorf("Snapshot() = %v, Evicted() = %v, want %v %v", got, r.Evicted(), []int{3, 4, 5}, 2)
+	}
+	r.Pop()
+	r.MPush(6, 7, 8, 9, 10)
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{8, 9, 10}) || r.Evicted() != 6 {
+		t.Errorf("Snapshot() = %v, Evicted() = %v, want %v %v", got, r.Evicted(), []int{8, 9, 10}, 6)
+	}
+	if !r.TryPush(11) || r.Peek() != 9 {
+		t.Errorf("TryPush() Peek() = %v, want %v", r.Peek(), 9)
+	}
+
+	r = New[int](3)
+	r.MPush(1, 2, 3, 4)
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{1, 2, 3}) || r.Evicted() != 0 || r.TryPush(5) {
+		t.Errorf("Snapshot() = %v, want %v", got, []int{1, 2, 3})
+	}
+}
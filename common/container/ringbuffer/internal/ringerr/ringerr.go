@@ -0,0 +1,12 @@
+// Package ringerr 各种环形队列共用的错误
+// ringbuffer和它的fix、round、unbounded子包都引用这里的同一个值，errors.Is可以跨队列类型判断
+package ringerr
+
+import "errors"
+
+var (
+	// ErrEmpty 队列为空
+	ErrEmpty = errors.New("ringbuffer: empty")
+	// ErrFull 队列已满
+	ErrFull = errors.New("ringbuffer: full")
+)
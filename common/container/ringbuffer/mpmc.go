@@ -0,0 +1,121 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+
+	"github.com/ahKevinXy/go-web-tools/common/math"
+)
+
+// MaxMPMCSize MPMC的最大容量
+const MaxMPMCSize = 1 << 62
+
+// 缓存行大小，读写位置分开存放避免伪共享
+const cacheLineSize = 64
+
+// MPMC 无锁的多生产者多消费者有界队列
+// 每个槽位带一个序号，生产者和消费者各自通过CAS抢占位置，不需要加锁
+// 容量向上取2的幂；队列满或空时不等待，直接返回失败
+// https://www.1024cores.net/home/lock-free-algorithms/queues/bounded-mpmc-queue
+type MPMC[T any] struct {
+	_    [cacheLineSize]byte
+	enq  uint64 // 写位置，原子操作
+	_    [cacheLineSize - 8]byte
+	deq  uint64 // 读位置，原子操作
+	_    [cacheLineSize - 8]byte
+	mask uint64
+	// 槽位的序号，原子操作；seq等于写位置时可以写入，等于读位置+1时可以读出
+	// 和元素分开存放，32位平台上切片首地址8字节对齐，每个序号都能原子操作
+	seqs []uint64
+	vals []T // 槽位的元素
+}
+
+// NewMPMC 创建
+// size 队列容量，向上取2的幂
+func NewMPMC[T any](size uint64) *MPMC[T] {
+	if size == 0 {
+		panic("size must be greater than 0")
+	}
+	if size > MaxMPMCSize {
+		panic("size is too large")
+	}
+	size = math.RoundUpPowOf2(size)
+	q := &MPMC[T]{
+		mask: size - 1,
+		seqs: make([]uint64, size),
+		vals: make([]T, size),
+	}
+	for i := range q.seqs {
+		q.seqs[i] = uint64(i)
+	}
+	return q
+}
+
+// TryPush 写入队尾
+// 队列已满时返回false
+func (q *MPMC[T]) TryPush(v T) bool {
+	pos := atomic.LoadUint64(&q.enq)
+	for {
+		i := pos & q.mask
+		seq := atomic.LoadUint64(&q.seqs[i])
+		switch diff := int64(seq - pos); {
+		case diff == 0:
+			// 槽位空闲，抢占写位置
+			if atomic.CompareAndSwapUint64(&q.enq, pos, pos+1) {
+				q.vals[i] = v
+				atomic.StoreUint64(&q.seqs[i], pos+1)
+				return true
+			}
+			pos = atomic.LoadUint64(&q.enq)
+		case diff < 0:
+			// 槽位上一轮的元素还没有被读出
+			return false
+		default:
+			// 其他生产者已经写入，重新读取写位置
+			pos = atomic.LoadUint64(&q.enq)
+		}
+	}
+}
+
+// TryPop 读取队头
+// 队列为空时ok为false
+func (q *MPMC[T]) TryPop() (v T, ok bool) {
+	pos := atomic.LoadUint64(&q.deq)
+	for {
+		i := pos & q.mask
+		seq := atomic.LoadUint64(&q.seqs[i])
+		switch diff := int64(seq - (pos + 1)); {
+		case diff == 0:
+			// 槽位已写入，抢占读位置
+			if atomic.CompareAndSwapUint64(&q.deq, pos, pos+1) {
+				var zero T
+				v, q.vals[i] = q.vals[i], zero
+				// 留给下一轮的写入
+				atomic.StoreUint64(&q.seqs[i], pos+q.mask+1)
+				return v, true
+			}
+			pos = atomic.LoadUint64(&q.deq)
+		case diff < 0:
+			// 槽位还没有写入
+			return v, false
+		default:
+			// 其他消费者已经读出，重新读取读位置
+			pos = atomic.LoadUint64(&q.deq)
+		}
+	}
+}
+
+// Len 元素数量
+// 并发修改时只是近似值
+func (q *MPMC[T]) Len() uint64 {
+	deq := atomic.LoadUint64(&q.deq)
+	enq := atomic.LoadUint64(&q.enq)
+	if enq < deq {
+		return 0
+	}
+	return math.Min(enq-deq, q.Cap())
+}
+
+// Cap 容量
+func (q *MPMC[T]) Cap() uint64 {
+	return q.mask + 1
+}
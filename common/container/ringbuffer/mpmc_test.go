@@ -0,0 +1,142 @@
+package ringbuffer
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/fix"
+)
+
+func TestMPMC(t *testing.T) {
+	q := NewMPMC[int](3)
+	if q.Cap() != 4 {
+		t.Errorf("Cap() = %v, want %v", q.Cap(), 4)
+	}
+	for i := 0; i < 4; i++ {
+		if !q.TryPush(i) {
+			t.Fatalf("TryPush(%v) = false, want true", i)
+		}
+	}
+	if q.TryPush(4) || q.Len() != 4 {
+		t.Errorf("TryPush() on full queue = true, Len() = %v", q.Len())
+	}
+	for i := 0; i < 4; i++ {
+		if v, ok := q.TryPop(); v != i || !ok {
+			t.Errorf("TryPop() = %v %v, want %v", v, ok, i)
+		}
+	}
+	if _, ok := q.TryPop(); ok {
+		t.Errorf("TryPop() on empty queue ok = %v, want %v", ok, false)
+	}
+}
+
+// 多个生产者和消费者并发，每个值恰好被读出一次
+func TestMPMCStress(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 20000
+	q := NewMPMC[int](64)
+	seen := make([]int32, producers*perProducer)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for !q.TryPush(p*perProducer + i) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	var received int64
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func() {
+			defer cwg.Done()
+			for atomic.LoadInt64(&received) < producers*perProducer {
+				v, ok := q.TryPop()
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				atomic.AddInt32(&seen[v], 1)
+				atomic.AddInt64(&received, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	cwg.Wait()
+	for v, n := range seen {
+		if n != 1 {
+			t.Fatalf("value %v received %v times, want 1", v, n)
+		}
+	}
+}
+
+// 互斥锁保护的队列，作为对照
+type mutexQueue[T any] struct {
+	mu   sync.Mutex
+	ring *fix.Ring[T]
+}
+
+func (q *mutexQueue[T]) TryPush(v T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.ring.TryPush(v)
+}
+
+func (q *mutexQueue[T]) TryPop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.ring.TryPop()
+}
+
+// 每个goroutine交替写入和读取
+func benchmarkQueue(b *testing.B, push func(int) bool, pop func() (int, bool)) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for !push(1) {
+				runtime.Gosched()
+			}
+			for {
+				if _, ok := pop(); ok {
+					break
+				}
+				runtime.Gosched()
+			}
+		}
+	})
+}
+
+func BenchmarkMPMC(b *testing.B) {
+	q := NewMPMC[int](1024)
+	benchmarkQueue(b, q.TryPush, q.TryPop)
+}
+
+func BenchmarkMutexQueue(b *testing.B) {
+	q := &mutexQueue[int]{ring: fix.New[int](1024)}
+	benchmarkQueue(b, q.TryPush, q.TryPop)
+}
+
+func BenchmarkChannel(b *testing.B) {
+	ch := make(chan int, 1024)
+	push := func(v int) bool {
+		select {
+		case ch <- v:
+			return true
+		default:
+			return false
+		}
+	}
+	pop := func() (int, bool) {
+		select {
+		case v := <-ch:
+			return v, true
+		default:
+			return 0, false
+		}
+	}
+	benchmarkQueue(b, push, pop)
+}
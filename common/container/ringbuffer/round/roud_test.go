@@ -0,0 +1,55 @@
+package round
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOverwrite(t *testing.T) {
+	// 长度向上取2的幂
+	r := NewOverwrite[int](3)
+	if r.Cap() != 4 {
+		t.Fatalf("Cap() = %v, want %v", r.Cap(), 4)
+	}
+	for i := 1; i <= 6; i++ {
+		r.Push(i)
+	}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{3, 4, 5, 6}) || r.Evicted() != 2 {
+		t.Errorf("Snapshot() = %v, Evicted() = %v, want %v %v", got, r.Evicted(), []int{3, 4, 5, 6}, 2)
+	}
+	// 读出一个后写入超过剩余长度的元素，覆盖跨越了数组末尾
+	r.Pop()
+	r.MPush(7, 8, 9)
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{6, 7, 8, 9}) || r.Evicted() != 4 {
+		t.Errorf("Snapshot() = %v, Evicted() = %v, want %v %v", got, r.Evicted(), []int{6, 7, 8, 9}, 4)
+	}
+	// 一次写入超过容量，只留下最后4个
+	r.MPush(10, 11, 12, 13, 14, 15)
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{12, 13, 14, 15}) || r.Evicted() != 10 {
+		t.Errorf("Snapshot() = %v, Evicted() = %v, want %v %v", got, r.Evicted(), []int{12, 13, 14, 15}, 10)
+	}
+	if !r.TryPush(16) || r.Peek() != 13 {
+		t.Errorf("TryPush() Peek() = %v, want %v", r.Peek(), 13)
+	}
+	if got := r.MPop(4); !reflect.DeepEqual(got, []int{13, 14, 15, 16}) || !r.Empty() {
+		t.Errorf("MPop() = %v, want %v", got, []int{13, 14, 15, 16})
+	}
+
+	r.Push(1)
+	r.Reset()
+	if r.Len() != 0 || r.Evicted() != 0 || len(r.Snapshot()) != 0 {
+		t.Errorf("Len() = %v, Evicted() = %v after Reset(), want 0, 0", r.Len(), r.Evicted())
+	}
+
+	r = New[int](4)
+	r.MPush(1, 2, 3, 4, 5)
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) || r.Evicted() != 0 || r.TryPush(5) {
+		t.Errorf("Snapshot() = %v, want %v", got, []int{1, 2, 3, 4})
+	}
+	defer func() {
+		if err := recover(); err != ErrFull {
+			t.Errorf("Push() panic = %v, want %v", err, ErrFull)
+		}
+	}()
+	r.Push(5)
+}
@@ -3,13 +3,19 @@ package unbounded
 import (
 	"math"
 
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/internal/ringerr"
 	mmath "github.com/ahKevinXy/go-web-tools/common/math"
 )
 
+// ErrEmpty 队列为空
+// 空队列调用Pop、Peek会panic(ErrEmpty)
+var ErrEmpty = ringerr.ErrEmpty
+
 // 最大长度
 const MaxSize = math.MaxInt64
 
 // 动态扩展长度
+// 空队列调用Pop、Peek会panic(ErrEmpty)，不确定是否为空时使用TryPop、TryPeek
 // 非线程安全，请加锁
 type Ring[T any] struct {
 	in   uint64 // 写索引
@@ -32,7 +38,7 @@ func New[T any](initSize uint64) *Ring[T] {
 // 弹出队头元素
 func (r *Ring[T]) Pop() T {
 	if r.Empty() {
-		panic("ring emtpy")
+		panic(ErrEmpty)
 	}
 	out := r.out % r.size
 	r.out++
@@ -42,7 +48,7 @@ func (r *Ring[T]) Pop() T {
 // 队头元素
 func (r *Ring[T]) Peek() T {
 	if r.Empty() {
-		panic("ring emtpy")
+		panic(ErrEmpty)
 	}
 	return r.data[r.out%r.size]
 }
@@ -57,6 +63,24 @@ func (r *Ring[T]) Push(e T) {
 	r.data[in] = e
 }
 
+// 弹出队头元素
+// 队列为空时ok为false
+func (r *Ring[T]) TryPop() (e T, ok bool) {
+	if r.Empty() {
+		return e, false
+	}
+	return r.Pop(), true
+}
+
+// 队头元素
+// 队列为空时ok为false
+func (r *Ring[T]) TryPeek() (e T, ok bool) {
+	if r.Empty() {
+		return e, false
+	}
+	return r.Peek(), true
+}
+
 // 写入队尾
 func (r *Ring[T]) MPush(elems ...T) {
 	size := uint64(len(elems))
@@ -0,0 +1,126 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/ringbuffer/unbounded"
+)
+
+// UnboundedChan 无界channel
+// 后台goroutine把In()收到的元素转发到Out()，Out()来不及读时积压在unbounded.Ring里，写入In()不会等待读取
+// 关闭In()后，积压的元素全部转发完再关闭Out()
+type UnboundedChan[T any] struct {
+	in        chan T
+	out       chan T
+	buf       *unbounded.Ring[T]
+	initSize  int
+	backlog   int64 // 积压的数量，原子操作
+	threshold int
+	warn      func(backlog int)
+	warned    bool // 已经告警，积压降到阈值一半及以下后才会再次告警
+}
+
+// NewUnboundedChan 创建
+// initSize In()和Out()的缓冲长度，也是积压缓冲区的初始长度
+func NewUnboundedChan[T any](initSize int) *UnboundedChan[T] {
+	return NewUnboundedChanWithWarning[T](initSize, 0, nil)
+}
+
+// NewUnboundedChanWithWarning 创建带积压告警的无界channel
+// 积压达到threshold时调用一次warn，降到threshold一半及以下后才会再次告警
+// threshold不大于0或warn为nil时不告警
+// warn在转发的goroutine里调用，不能阻塞
+func NewUnboundedChanWithWarning[T any](initSize, threshold int, warn func(backlog int)) *UnboundedChan[T] {
+	if initSize <= 0 {
+		panic("initSize must be greater than 0")
+	}
+	c := &UnboundedChan[T]{
+		in:        make(chan T, initSize),
+		out:       make(chan T, initSize),
+		buf:       unbounded.New[T](uint64(initSize)),
+		initSize:  initSize,
+		threshold: threshold,
+		warn:      warn,
+	}
+	go c.run()
+	return c
+}
+
+// In 写入的channel
+// 写完后关闭它，Out()会在积压转发完后关闭
+func (c *UnboundedChan[T]) In() chan<- T {
+	return c.in
+}
+
+// Out 读取的channel
+// 元素顺序和写入顺序相同
+func (c *UnboundedChan[T]) Out() <-chan T {
+	return c.out
+}
+
+// Len 还没有被读出的元素数量，包括两个channel里的和积压的
+func (c *UnboundedChan[T]) Len() int {
+	return len(c.in) + c.Backlog() + len(c.out)
+}
+
+// Backlog 积压缓冲区里的元素数量
+func (c *UnboundedChan[T]) Backlog() int {
+	return int(atomic.LoadInt64(&c.backlog))
+}
+
+// 转发
+func (c *UnboundedChan[T]) run() {
+	defer close(c.out)
+	for {
+		if c.buf.Empty() {
+			v, ok := <-c.in
+			if !ok {
+				return
+			}
+			// 没有积压时尽量直接转发
+			select {
+			case c.out <- v:
+			default:
+				c.push(v)
+			}
+			continue
+		}
+
+		select {
+		case v, ok := <-c.in:
+			if !ok {
+				// 转发完积压的元素再关闭Out()
+				for !c.buf.Empty() {
+					c.out <- c.pop()
+				}
+				return
+			}
+			c.push(v)
+		case c.out <- c.buf.Peek():
+			c.pop()
+		}
+	}
+}
+
+func (c *UnboundedChan[T]) push(v T) {
+	c.buf.Push(v)
+	backlog := atomic.AddInt64(&c.backlog, 1)
+	if c.warn != nil && c.threshold > 0 && !c.warned && backlog >= int64(c.threshold) {
+		c.warned = true
+		c.warn(int(backlog))
+	}
+}
+
+func (c *UnboundedChan[T]) pop() T {
+	v := c.buf.Pop()
+	if c.buf.Empty() && c.buf.Cap() > uint64(c.initSize) {
+		// 积压清空后缩小到初始长度，释放扩容的空间
+		c.buf = unbounded.New[T](uint64(c.initSize))
+	}
+	// 缩小之后再减少计数，Backlog()为0时缓冲区已经是初始长度
+	backlog := atomic.AddInt64(&c.backlog, -1)
+	if c.warned && backlog <= int64(c.threshold/2) {
+		c.warned = false
+	}
+	return v
+}
@@ -0,0 +1,126 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUnboundedChan(t *testing.T) {
+	var warned []int
+	c := NewUnboundedChanWithWarning[int](4, 100, func(backlog int) {
+		warned = append(warned, backlog)
+	})
+	// 没有读取者时写入不会阻塞
+	const n = 1000
+	for i := 0; i < n; i++ {
+		c.In() <- i
+	}
+	close(c.In())
+	for i := 0; i < n; i++ {
+		if v := <-c.Out(); v != i {
+			t.Fatalf("Out() = %v, want %v", v, i)
+		}
+	}
+	if _, ok := <-c.Out(); ok {
+		t.Errorf("Out() not closed after In() closed")
+	}
+	if len(warned) != 1 || warned[0] != 100 {
+		t.Errorf("warn called with %v, want [100]", warned)
+	}
+	if c.Len() != 0 || c.buf.Cap() != 4 {
+		t.Errorf("Len() = %v, Cap() = %v, want 0 4", c.Len(), c.buf.Cap())
+	}
+}
+
+func TestUnboundedChanConcurrent(t *testing.T) {
+	c := NewUnboundedChan[int](8)
+	const n = 100000
+	go func() {
+		for i := 0; i < n; i++ {
+			c.In() <- i
+			if i%1000 == 0 {
+				// 让读取者追上来，积压反复清空再增长
+				time.Sleep(time.Millisecond)
+			}
+		}
+		close(c.In())
+	}()
+	want := 0
+	for v := range c.Out() {
+		if v != want {
+			t.Fatalf("Out() = %v, want %v", v, want)
+		}
+		want++
+		if want%5000 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if want != n {
+		t.Errorf("received %v elements, want %v", want, n)
+	}
+}
+
+func TestUnboundedChanWarning(t *testing.T) {
+	var warned int32
+	c := NewUnboundedChanWithWarning[int](1, 1, func(int) {
+		atomic.AddInt32(&warned, 1)
+	})
+	// 阈值为1时，积压清空后重新告警
+	for round := 0; round < 3; round++ {
+		prev := atomic.LoadInt32(&warned)
+		for i := 0; i < 4; i++ {
+			c.In() <- i
+		}
+		waitFor(t, func() bool { return atomic.LoadInt32(&warned) > prev })
+		for i := 0; i < 4; i++ {
+			<-c.Out()
+		}
+		waitFor(t, func() bool { return c.Len() == 0 })
+	}
+
+	// 阈值不大于0时不告警
+	c = NewUnboundedChanWithWarning[int](1, 0, func(int) {
+		t.Errorf("warn called with threshold 0")
+	})
+	for i := 0; i < 10; i++ {
+		c.In() <- i
+	}
+	waitFor(t, func() bool { return c.Backlog() > 0 })
+	close(c.In())
+	for range c.Out() {
+	}
+}
+
+func TestUnboundedChanShrink(t *testing.T) {
+	c := NewUnboundedChan[int](4)
+	for i := 0; i < 1000; i++ {
+		c.In() <- i
+	}
+	waitFor(t, func() bool { return c.Backlog() > 4 })
+	for i := 0; i < 1000; i++ {
+		<-c.Out()
+	}
+	// 积压清空后缓冲区缩小到初始长度，channel仍然可以使用
+	waitFor(t, func() bool { return c.Backlog() == 0 })
+	if c.buf.Cap() != 4 {
+		t.Errorf("Cap() = %v, want %v", c.buf.Cap(), 4)
+	}
+	c.In() <- 1000
+	if v := <-c.Out(); v != 1000 {
+		t.Errorf("Out() = %v, want %v", v, 1000)
+	}
+	close(c.In())
+}
+
+// 等待cond成立，超时失败
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
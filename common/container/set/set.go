@@ -1,5 +1,33 @@
 package set
 
+import (
+	"encoding/json"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+// Interface 集合的公共方法
+// Set、Sync和Sorted都实现了这个接口
+type Interface[T comparable] interface {
+	Add(elem T)
+	Remove(elem T)
+	Contains(elem T) bool
+	Len() int
+	Empty() bool
+	Range(fn func(elem T) bool)
+	ToSlice() []T
+}
+
+var (
+	_ Interface[int] = (*Set[int])(nil)
+	_ Interface[int] = (*Sync[int])(nil)
+	_ Interface[int] = (*Sorted[int])(nil)
+)
+
+// Set 基于map的集合
+// 零值可以直接使用
+// 非线程安全，请加锁
 type Set[T comparable] struct {
 	m map[T]struct{}
 }
@@ -8,8 +36,21 @@ func New[T comparable]() *Set[T] {
 	return &Set[T]{m: map[T]struct{}{}}
 }
 
+// FromSlice 从切片创建集合
+// 重复的元素只保留一个
+func FromSlice[T comparable](elems []T) *Set[T] {
+	s := &Set[T]{m: make(map[T]struct{}, len(elems))}
+	for _, elem := range elems {
+		s.m[elem] = struct{}{}
+	}
+	return s
+}
+
 // Add 加入集合
 func (s *Set[T]) Add(elem T) {
+	if s.m == nil {
+		s.m = map[T]struct{}{}
+	}
 	s.m[elem] = struct{}{}
 }
 
@@ -33,3 +74,133 @@ func (s *Set[T]) Len() int {
 func (s *Set[T]) Empty() bool {
 	return s.Len() == 0
 }
+
+// Range 遍历集合
+// fn返回false时停止遍历，遍历顺序不固定
+func (s *Set[T]) Range(fn func(elem T) bool) {
+	for elem := range s.m {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// ToSlice 转换为切片
+// 元素顺序不固定，需要有序时使用SortedSlice
+func (s *Set[T]) ToSlice() []T {
+	elems := make([]T, 0, len(s.m))
+	for elem := range s.m {
+		elems = append(elems, elem)
+	}
+	return elems
+}
+
+// Clone 复制集合
+func (s *Set[T]) Clone() *Set[T] {
+	c := &Set[T]{m: make(map[T]struct{}, len(s.m))}
+	for elem := range s.m {
+		c.m[elem] = struct{}{}
+	}
+	return c
+}
+
+// Union 并集
+// 返回新的集合，不修改s和other
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	big, small := s, other
+	if small.Len() > big.Len() {
+		big, small = small, big
+	}
+	u := big.Clone()
+	for elem := range small.m {
+		u.m[elem] = struct{}{}
+	}
+	return u
+}
+
+// Intersection 交集
+// 返回新的集合，不修改s和other
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	big, small := s, other
+	if small.Len() > big.Len() {
+		big, small = small, big
+	}
+	i := New[T]()
+	for elem := range small.m {
+		if big.Contains(elem) {
+			i.m[elem] = struct{}{}
+		}
+	}
+	return i
+}
+
+// Difference 差集
+// 返回在s中但不在other中的元素
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	d := New[T]()
+	for elem := range s.m {
+		if !other.Contains(elem) {
+			d.m[elem] = struct{}{}
+		}
+	}
+	return d
+}
+
+// SymmetricDifference 对称差集
+// 返回只在其中一个集合中的元素
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	d := s.Difference(other)
+	for elem := range other.m {
+		if !s.Contains(elem) {
+			d.m[elem] = struct{}{}
+		}
+	}
+	return d
+}
+
+// IsSubset s是否是other的子集
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for elem := range s.m {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset s是否是other的超集
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Equal 两个集合的元素是否相同
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
+
+// MarshalJSON 实现json.Marshaler
+// 编码为数组，元素顺序不固定；非指针的Set字段要传结构体指针给json.Marshal才会调用
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToSlice())
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+// 从数组解码，替换集合原有的元素
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var elems []T
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	*s = *FromSlice(elems)
+	return nil
+}
+
+// SortedSlice 转换为从小到大排序的切片
+func SortedSlice[T constraints.Ordered](s *Set[T]) []T {
+	elems := s.ToSlice()
+	slices.Sort(elems)
+	return elems
+}
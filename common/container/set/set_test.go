@@ -0,0 +1,117 @@
+package set
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestAlgebra(t *testing.T) {
+	a, b := FromSlice([]int{1, 2, 3, 4}), FromSlice([]int{3, 4, 5})
+	tests := []struct {
+		name string
+		got  *Set[int]
+		want []int
+	}{
+		{"Union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"Intersection", a.Intersection(b), []int{3, 4}},
+		{"Difference", a.Difference(b), []int{1, 2}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 2, 5}},
+	}
+	for _, tt := range tests {
+		if got := SortedSlice(tt.got); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if a.Len() != 4 || b.Len() != 3 {
+		t.Errorf("operands modified: %v %v", a.Len(), b.Len())
+	}
+
+	sub := FromSlice([]int{3, 4})
+	if !sub.IsSubset(a) || !a.IsSuperset(sub) || sub.IsSuperset(a) {
+		t.Errorf("IsSubset() IsSuperset() = %v %v, want true true", sub.IsSubset(a), a.IsSuperset(sub))
+	}
+	if !a.Equal(a.Clone()) || a.Equal(b) {
+		t.Errorf("Equal() = %v %v, want true false", a.Equal(a.Clone()), a.Equal(b))
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Perms Set[string] `json:"perms"`
+	}
+	if err := json.Unmarshal([]byte(`{"perms":["read","write","read"]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if got := SortedSlice(&v.Perms); !reflect.DeepEqual(got, []string{"read", "write"}) {
+		t.Errorf("UnmarshalJSON() = %v, want %v", got, []string{"read", "write"})
+	}
+	v.Perms.Remove("write")
+	b, _ := json.Marshal(&v)
+	if string(b) != `{"perms":["read"]}` {
+		t.Errorf("MarshalJSON() = %s", b)
+	}
+}
+
+func TestSync(t *testing.T) {
+	s := SyncFromSlice([]string{"read"})
+	snap := s.Snapshot()
+	s.AddAll("write", "admin")
+	s.Remove("admin")
+	if snap.Len() != 1 || s.Len() != 2 || !s.Contains("write") {
+		t.Errorf("Len() = %v %v, want 1 2", snap.Len(), s.Len())
+	}
+	// 修改快照不影响Sync
+	snap.Add("root")
+	if s.Contains("root") || s.Len() != 2 {
+		t.Errorf("Snapshot() shares elements with Sync")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Add(strconv.Itoa(i*100 + j))
+				s.Contains("read")
+			}
+		}(i)
+	}
+	wg.Wait()
+	if s.Len() != 402 {
+		t.Errorf("Len() = %v, want %v", s.Len(), 402)
+	}
+}
+
+func TestSorted(t *testing.T) {
+	s := SortedFromSlice([]int{50, 10, 30, 10, 40})
+	s.Add(20)
+	s.Remove(40)
+	if got := s.ToSlice(); !reflect.DeepEqual(got, []int{10, 20, 30, 50}) {
+		t.Errorf("ToSlice() = %v, want %v", got, []int{10, 20, 30, 50})
+	}
+	tests := []struct {
+		x                  int
+		floor, ceiling     int
+		floorOk, ceilingOk bool
+	}{
+		{5, 0, 10, false, true},
+		{10, 10, 10, true, true},
+		{35, 30, 50, true, true},
+		{60, 50, 0, true, false},
+	}
+	for _, tt := range tests {
+		if got, ok := s.Floor(tt.x); got != tt.floor || ok != tt.floorOk {
+			t.Errorf("Floor(%v) = %v %v, want %v %v", tt.x, got, ok, tt.floor, tt.floorOk)
+		}
+		if got, ok := s.Ceiling(tt.x); got != tt.ceiling || ok != tt.ceilingOk {
+			t.Errorf("Ceiling(%v) = %v %v, want %v %v", tt.x, got, ok, tt.ceiling, tt.ceilingOk)
+		}
+	}
+	if got := s.Between(15, 50); !reflect.DeepEqual(got, []int{20, 30, 50}) {
+		t.Errorf("Between() = %v, want %v", got, []int{20, 30, 50})
+	}
+}
@@ -0,0 +1,149 @@
+package set
+
+import (
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+// Sorted 有序集合
+// 基于有序切片，查询O(log n)，插入和删除O(n)，适合读多写少的场景
+// 零值可以直接使用
+// 非线程安全，请加锁
+type Sorted[T constraints.Ordered] struct {
+	elems []T // 从小到大排序
+}
+
+func NewSorted[T constraints.Ordered]() *Sorted[T] {
+	return &Sorted[T]{}
+}
+
+// SortedFromSlice 从切片创建有序集合
+// 重复的元素只保留一个
+func SortedFromSlice[T constraints.Ordered](elems []T) *Sorted[T] {
+	s := &Sorted[T]{elems: slices.Clone(elems)}
+	slices.Sort(s.elems)
+	s.elems = slices.Compact(s.elems)
+	return s
+}
+
+// Add 加入集合
+func (s *Sorted[T]) Add(elem T) {
+	i, found := slices.BinarySearch(s.elems, elem)
+	if !found {
+		s.elems = slices.Insert(s.elems, i, elem)
+	}
+}
+
+// Remove 移出集合
+func (s *Sorted[T]) Remove(elem T) {
+	i, found := slices.BinarySearch(s.elems, elem)
+	if found {
+		s.elems = slices.Delete(s.elems, i, i+1)
+	}
+}
+
+// Contains 是否包含元素
+func (s *Sorted[T]) Contains(elem T) bool {
+	_, found := slices.BinarySearch(s.elems, elem)
+	return found
+}
+
+// Len 集合长度
+func (s *Sorted[T]) Len() int {
+	return len(s.elems)
+}
+
+// Empty 集合是否为空
+func (s *Sorted[T]) Empty() bool {
+	return s.Len() == 0
+}
+
+// Range 从小到大遍历集合
+// fn返回false时停止遍历
+func (s *Sorted[T]) Range(fn func(elem T) bool) {
+	for _, elem := range s.elems {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// RangeDesc 从大到小遍历集合
+// fn返回false时停止遍历
+func (s *Sorted[T]) RangeDesc(fn func(elem T) bool) {
+	for i := len(s.elems) - 1; i >= 0; i-- {
+		if !fn(s.elems[i]) {
+			return
+		}
+	}
+}
+
+// RangeBetween 从小到大遍历[lo,hi]内的元素
+// fn返回false时停止遍历
+func (s *Sorted[T]) RangeBetween(lo, hi T, fn func(elem T) bool) {
+	i, _ := slices.BinarySearch(s.elems, lo)
+	for ; i < len(s.elems) && s.elems[i] <= hi; i++ {
+		if !fn(s.elems[i]) {
+			return
+		}
+	}
+}
+
+// Between [lo,hi]内的元素，从小到大排序
+func (s *Sorted[T]) Between(lo, hi T) []T {
+	if lo > hi {
+		return []T{}
+	}
+	i, _ := slices.BinarySearch(s.elems, lo)
+	j, found := slices.BinarySearch(s.elems, hi)
+	if found {
+		j++
+	}
+	return slices.Clone(s.elems[i:j])
+}
+
+// ToSlice 转换为从小到大排序的切片
+func (s *Sorted[T]) ToSlice() []T {
+	return slices.Clone(s.elems)
+}
+
+// Min 最小的元素
+// 集合为空时ok为false
+func (s *Sorted[T]) Min() (elem T, ok bool) {
+	if len(s.elems) == 0 {
+		return elem, false
+	}
+	return s.elems[0], true
+}
+
+// Max 最大的元素
+// 集合为空时ok为false
+func (s *Sorted[T]) Max() (elem T, ok bool) {
+	if len(s.elems) == 0 {
+		return elem, false
+	}
+	return s.elems[len(s.elems)-1], true
+}
+
+// Floor 小于等于x的最大元素
+// 不存在时ok为false
+func (s *Sorted[T]) Floor(x T) (elem T, ok bool) {
+	i, found := slices.BinarySearch(s.elems, x)
+	if found {
+		return s.elems[i], true
+	}
+	if i == 0 {
+		return elem, false
+	}
+	return s.elems[i-1], true
+}
+
+// Ceiling 大于等于x的最小元素
+// 不存在时ok为false
+func (s *Sorted[T]) Ceiling(x T) (elem T, ok bool) {
+	i, _ := slices.BinarySearch(s.elems, x)
+	if i == len(s.elems) {
+		return elem, false
+	}
+	return s.elems[i], true
+}
@@ -0,0 +1,115 @@
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Sync 并发安全的集合
+// 写时复制：每次修改复制一份新的集合再原子替换，读操作不加锁
+// 修改的代价是O(n)，适合读多写少的场景，例如权限、白名单
+type Sync[T comparable] struct {
+	mu sync.Mutex   // 串行化修改
+	v  atomic.Value // 当前快照 *Set[T]，替换后不再修改
+}
+
+func NewSync[T comparable]() *Sync[T] {
+	s := &Sync[T]{}
+	s.v.Store(New[T]())
+	return s
+}
+
+// SyncFromSlice 从切片创建并发安全的集合
+func SyncFromSlice[T comparable](elems []T) *Sync[T] {
+	s := &Sync[T]{}
+	s.v.Store(FromSlice(elems))
+	return s
+}
+
+// Add 加入集合
+// 元素已经存在时不复制
+func (s *Sync[T]) Add(elem T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := s.load()
+	if cur.Contains(elem) {
+		return
+	}
+	next := cur.Clone()
+	next.Add(elem)
+	s.v.Store(next)
+}
+
+// AddAll 批量加入集合
+// 只复制一次
+func (s *Sync[T]) AddAll(elems ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.load().Clone()
+	for _, elem := range elems {
+		next.Add(elem)
+	}
+	s.v.Store(next)
+}
+
+// Remove 移出集合
+// 元素不存在时不复制
+func (s *Sync[T]) Remove(elem T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := s.load()
+	if !cur.Contains(elem) {
+		return
+	}
+	next := cur.Clone()
+	next.Remove(elem)
+	s.v.Store(next)
+}
+
+// Replace 整体替换集合的元素
+func (s *Sync[T]) Replace(elems []T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.v.Store(FromSlice(elems))
+}
+
+// Contains 是否包含元素
+func (s *Sync[T]) Contains(elem T) bool {
+	return s.load().Contains(elem)
+}
+
+// Len 集合长度
+func (s *Sync[T]) Len() int {
+	return s.load().Len()
+}
+
+// Empty 集合是否为空
+func (s *Sync[T]) Empty() bool {
+	return s.Len() == 0
+}
+
+// Range 遍历集合
+// 遍历的是调用时的快照，遍历期间的修改不可见
+func (s *Sync[T]) Range(fn func(elem T) bool) {
+	s.load().Range(fn)
+}
+
+// ToSlice 转换为切片
+func (s *Sync[T]) ToSlice() []T {
+	return s.load().ToSlice()
+}
+
+// Snapshot 当前集合的副本
+// 副本和Sync互不影响，修改副本不会改变Sync，之后对Sync的修改也不会反映到副本；复制的代价是O(n)
+func (s *Sync[T]) Snapshot() *Set[T] {
+	return s.load().Clone()
+}
+
+// 当前的快照，只读，不能修改
+func (s *Sync[T]) load() *Set[T] {
+	if cur, ok := s.v.Load().(*Set[T]); ok {
+		return cur
+	}
+	// 零值还没有存储过快照
+	return New[T]()
+}
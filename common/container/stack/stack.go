@@ -1,36 +1,84 @@
-package deque
+package stack
 
-import "github.com/ahKevinXy/go-web-tools/common/container/list"
+import (
+	"errors"
 
+	"github.com/ahKevinXy/go-web-tools/common/container/deque"
+)
+
+// ErrEmpty 栈为空
+// 空栈调用Pop、Peek会panic(ErrEmpty)
+var ErrEmpty = errors.New("stack: empty")
+
+// Stack 栈
+// 空栈调用Pop、Peek会panic(ErrEmpty)，不确定是否为空时使用TryPop、TryPeek
+// 非线程安全，请加锁
 type Stack[T any] struct {
-	l *list.List[T]
+	d *deque.Deque[T]
 }
 
 func New[T any]() *Stack[T] {
-	return &Stack[T]{l: list.New[T]()}
+	return &Stack[T]{d: deque.New[T]()}
 }
 
 // Push 入栈
 func (s *Stack[T]) Push(elem T) {
-	s.l.PushBack(elem)
+	s.d.PushBack(elem)
 }
 
 // Pop 出栈
+// 栈为空会panic(ErrEmpty)
 func (s *Stack[T]) Pop() T {
-	return s.l.RemoveBack()
+	elem, ok := s.d.TryPopBack()
+	if !ok {
+		panic(ErrEmpty)
+	}
+	return elem
+}
+
+// TryPop 出栈
+// 栈为空时ok为false
+func (s *Stack[T]) TryPop() (T, bool) {
+	return s.d.TryPopBack()
 }
 
 // Peek 栈顶元素
+// 栈为空会panic(ErrEmpty)
 func (s *Stack[T]) Peek() T {
-	return s.l.Back().Value
+	elem, ok := s.d.PeekBack()
+	if !ok {
+		panic(ErrEmpty)
+	}
+	return elem
+}
+
+// TryPeek 栈顶元素
+// 栈为空时ok为false
+func (s *Stack[T]) TryPeek() (T, bool) {
+	return s.d.PeekBack()
+}
+
+// Range 从栈顶到栈底遍历
+// fn返回false时停止遍历，遍历期间不能修改栈
+func (s *Stack[T]) Range(fn func(elem T) bool) {
+	for i := s.d.Len() - 1; i >= 0; i-- {
+		if !fn(s.d.At(i)) {
+			return
+		}
+	}
+}
+
+// Clear 清空栈
+func (s *Stack[T]) Clear() {
+	s.d.Clear()
 }
 
 // Len 栈元素个数
 func (s *Stack[T]) Len() int {
-	return s.l.Len()
+	return s.d.Len()
 }
 
 // Empty 栈是否为空
 func (s *Stack[T]) Empty() bool {
-	return s.l.Empty()
+	return s.d.Empty()
 }
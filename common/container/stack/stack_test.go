@@ -0,0 +1,68 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	s := New[int]()
+	if _, ok := s.TryPop(); ok {
+		t.Errorf("TryPop() ok = true, want false")
+	}
+	if _, ok := s.TryPeek(); ok {
+		t.Errorf("TryPeek() ok = true, want false")
+	}
+	for i := 0; i < 100; i++ {
+		s.Push(i)
+	}
+	if elem, ok := s.TryPeek(); elem != 99 || !ok {
+		t.Errorf("TryPeek() = %v %v, want %v true", elem, ok, 99)
+	}
+	if elem := s.Peek(); elem != 99 {
+		t.Errorf("Peek() = %v, want %v", elem, 99)
+	}
+
+	var got []int
+	s.Range(func(elem int) bool {
+		got = append(got, elem)
+		return len(got) < 3
+	})
+	if want := []int{99, 98, 97}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Range() = %v, want %v", got, want)
+	}
+
+	for i := 99; i >= 50; i-- {
+		if elem := s.Pop(); elem != i {
+			t.Fatalf("Pop() = %v, want %v", elem, i)
+		}
+	}
+	if elem, ok := s.TryPop(); elem != 49 || !ok {
+		t.Errorf("TryPop() = %v %v, want %v true", elem, ok, 49)
+	}
+	if s.Len() != 49 {
+		t.Errorf("Len() = %v, want %v", s.Len(), 49)
+	}
+
+	s.Clear()
+	if !s.Empty() || s.Len() != 0 {
+		t.Errorf("Empty() = %v, Len() = %v after Clear(), want true, 0", s.Empty(), s.Len())
+	}
+}
+
+func TestStackEmptyPanic(t *testing.T) {
+	s := New[int]()
+	for name, fn := range map[string]func(){
+		"Pop":  func() { s.Pop() },
+		"Peek": func() { s.Peek() },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err != ErrEmpty {
+					t.Errorf("%v() panic = %v, want %v", name, err, ErrEmpty)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,452 @@
+package zset
+
+import (
+	"math/rand"
+
+	"golang.org/x/exp/constraints"
+)
+
+const (
+	maxLevel    = 32   // 跳表最大层数
+	probability = 0.25 // 每升高一层的概率
+)
+
+// Number 分数的类型
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Entry 成员和分数
+type Entry[M constraints.Ordered, S Number] struct {
+	Member M
+	Score  S
+}
+
+// ZSet 有序集合，类似Redis的ZSET
+// 跳表按照分数从小到大排序，分数相同时按照成员排序；map用于按成员查找分数
+// 增删改和按分数、排名查询都是O(log n)
+// 分数不能是NaN，否则会panic
+// 非线程安全，请加锁
+type ZSet[M constraints.Ordered, S Number] struct {
+	head   *node[M, S]
+	tail   *node[M, S]
+	level  int // 当前最高层数
+	length int
+	dict   map[M]*node[M, S]
+}
+
+// 跳表节点
+type node[M constraints.Ordered, S Number] struct {
+	member   M
+	score    S
+	backward *node[M, S] // 第0层的前一个节点，第一个节点为nil
+	levels   []level[M, S]
+}
+
+// 节点在某一层的后继
+type level[M constraints.Ordered, S Number] struct {
+	forward *node[M, S]
+	span    int // 到forward跨过的节点数量，用于计算排名
+}
+
+func New[M constraints.Ordered, S Number]() *ZSet[M, S] {
+	return &ZSet[M, S]{
+		head:  &node[M, S]{levels: make([]level[M, S], maxLevel)},
+		level: 1,
+		dict:  map[M]*node[M, S]{},
+	}
+}
+
+// Add 添加成员
+// 成员已经存在时更新分数，返回是否是新成员
+func (z *ZSet[M, S]) Add(member M, score S) bool {
+	checkScore(score)
+	if n, ok := z.dict[member]; ok {
+		if n.score != score {
+			z.updateScore(n, score)
+		}
+		return false
+	}
+	z.dict[member] = z.insert(member, score)
+	return true
+}
+
+// Incr 增加成员的分数
+// 成员不存在时以delta为分数添加，返回新的分数
+func (z *ZSet[M, S]) Incr(member M, delta S) S {
+	n, ok := z.dict[member]
+	if !ok {
+		z.Add(member, delta)
+		return delta
+	}
+	score := n.score + delta
+	checkScore(score)
+	if delta != 0 {
+		z.updateScore(n, score)
+	}
+	return score
+}
+
+// Remove 删除成员
+// 返回成员是否存在
+func (z *ZSet[M, S]) Remove(member M) bool {
+	n, ok := z.dict[member]
+	if !ok {
+		return false
+	}
+	z.delete(n)
+	delete(z.dict, member)
+	return true
+}
+
+// Score 成员的分数
+// 成员不存在时ok为false
+func (z *ZSet[M, S]) Score(member M) (score S, ok bool) {
+	n, ok := z.dict[member]
+	if !ok {
+		return score, false
+	}
+	return n.score, true
+}
+
+// Contains 是否包含成员
+func (z *ZSet[M, S]) Contains(member M) bool {
+	_, ok := z.dict[member]
+	return ok
+}
+
+// Rank 成员按照分数从小到大的排名，从0开始
+// 成员不存在时ok为false
+func (z *ZSet[M, S]) Rank(member M) (rank int, ok bool) {
+	n, ok := z.dict[member]
+	if !ok {
+		return 0, false
+	}
+	return z.rank(n) - 1, true
+}
+
+// RevRank 成员按照分数从大到小的排名，从0开始
+// 成员不存在时ok为false
+func (z *ZSet[M, S]) RevRank(member M) (rank int, ok bool) {
+	n, ok := z.dict[member]
+	if !ok {
+		return 0, false
+	}
+	return z.length - z.rank(n), true
+}
+
+// RangeByRank 按照分数从小到大，排名在[start,stop]内的成员
+// 和Redis的ZRANGE相同，负数表示从最后开始，-1是最后一个
+func (z *ZSet[M, S]) RangeByRank(start, stop int) []Entry[M, S] {
+	start, stop, ok := z.normalize(start, stop)
+	if !ok {
+		return []Entry[M, S]{}
+	}
+	entries := make([]Entry[M, S], 0, stop-start+1)
+	for n := z.byRank(start + 1); len(entries) < cap(entries); n = n.levels[0].forward {
+		entries = append(entries, n.entry())
+	}
+	return entries
+}
+
+// RevRangeByRank 按照分数从大到小，排名在[start,stop]内的成员
+// 负数表示从最后开始，-1是最后一个
+func (z *ZSet[M, S]) RevRangeByRank(start, stop int) []Entry[M, S] {
+	start, stop, ok := z.normalize(start, stop)
+	if !ok {
+		return []Entry[M, S]{}
+	}
+	entries := make([]Entry[M, S], 0, stop-start+1)
+	for n := z.byRank(z.length - start); len(entries) < cap(entries); n = n.backward {
+		entries = append(entries, n.entry())
+	}
+	return entries
+}
+
+// RangeByScore 按照分数从小到大，分数在[min,max]内的成员
+// limit不大于0时不限制数量
+func (z *ZSet[M, S]) RangeByScore(min, max S, limit int) []Entry[M, S] {
+	entries := []Entry[M, S]{}
+	for n := z.firstGE(min); n != nil && n.score <= max; n = n.levels[0].forward {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+		entries = append(entries, n.entry())
+	}
+	return entries
+}
+
+// RevRangeByScore 按照分数从大到小，分数在[min,max]内的成员
+// limit不大于0时不限制数量
+func (z *ZSet[M, S]) RevRangeByScore(max, min S, limit int) []Entry[M, S] {
+	entries := []Entry[M, S]{}
+	for n := z.lastLE(max); n != nil && n.score >= min; n = n.backward {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+		entries = append(entries, n.entry())
+	}
+	return entries
+}
+
+// CountByScore 分数在[min,max]内的成员数量
+func (z *ZSet[M, S]) CountByScore(min, max S) int {
+	first, last := z.firstGE(min), z.lastLE(max)
+	if first == nil || last == nil || first.score > max {
+		return 0
+	}
+	return z.rank(last) - z.rank(first) + 1
+}
+
+// Min 分数最小的成员
+// 集合为空时ok为false
+func (z *ZSet[M, S]) Min() (e Entry[M, S], ok bool) {
+	if n := z.head.levels[0].forward; n != nil {
+		return n.entry(), true
+	}
+	return e, false
+}
+
+// Max 分数最大的成员
+// 集合为空时ok为false
+func (z *ZSet[M, S]) Max() (e Entry[M, S], ok bool) {
+	if z.tail != nil {
+		return z.tail.entry(), true
+	}
+	return e, false
+}
+
+// PopMin 删除并返回分数最小的成员
+// 集合为空时ok为false
+func (z *ZSet[M, S]) PopMin() (e Entry[M, S], ok bool) {
+	if e, ok = z.Min(); ok {
+		z.Remove(e.Member)
+	}
+	return e, ok
+}
+
+// PopMax 删除并返回分数最大的成员
+// 集合为空时ok为false
+func (z *ZSet[M, S]) PopMax() (e Entry[M, S], ok bool) {
+	if e, ok = z.Max(); ok {
+		z.Remove(e.Member)
+	}
+	return e, ok
+}
+
+// Range 按照分数从小到大遍历
+// fn返回false时停止遍历，遍历期间不能修改集合
+func (z *ZSet[M, S]) Range(fn func(member M, score S) bool) {
+	for n := z.head.levels[0].forward; n != nil; n = n.levels[0].forward {
+		if !fn(n.member, n.score) {
+			return
+		}
+	}
+}
+
+// Len 成员数量
+func (z *ZSet[M, S]) Len() int {
+	return z.length
+}
+
+// Empty 是否为空
+func (z *ZSet[M, S]) Empty() bool {
+	return z.length == 0
+}
+
+// Clear 清空
+func (z *ZSet[M, S]) Clear() {
+	*z = *New[M, S]()
+}
+
+func (n *node[M, S]) entry() Entry[M, S] {
+	return Entry[M, S]{Member: n.member, Score: n.score}
+}
+
+// n是否排在(score,member)之前
+func (n *node[M, S]) less(score S, member M) bool {
+	return n.score < score || n.score == score && n.member < member
+}
+
+// NaN和任何值比较都是false，会破坏跳表的顺序
+func checkScore[S Number](score S) {
+	if score != score {
+		panic("zset: score is NaN")
+	}
+}
+
+// 随机层数，第i层的概率是probability^(i-1)
+func randomLevel() int {
+	level := 1
+	for level < maxLevel && rand.Float64() < probability {
+		level++
+	}
+	return level
+}
+
+// 插入新节点
+func (z *ZSet[M, S]) insert(member M, score S) *node[M, S] {
+	var update [maxLevel]*node[M, S]
+	var rank [maxLevel]int
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		if i < z.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && x.levels[i].forward.less(score, member) {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	lvl := randomLevel()
+	if lvl > z.level {
+		for i := z.level; i < lvl; i++ {
+			update[i] = z.head
+			update[i].levels[i].span = z.length
+		}
+		z.level = lvl
+	}
+	x = &node[M, S]{member: member, score: score, levels: make([]level[M, S], lvl)}
+	for i := 0; i < lvl; i++ {
+		x.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = x
+		// rank[0]-rank[i]是update[i]到update[0]跨过的节点数量
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	// 更高的层跨过了新节点
+	for i := lvl; i < z.level; i++ {
+		update[i].levels[i].span++
+	}
+
+	if update[0] != z.head {
+		x.backward = update[0]
+	}
+	if next := x.levels[0].forward; next != nil {
+		next.backward = x
+	} else {
+		z.tail = x
+	}
+	z.length++
+	return x
+}
+
+// 删除节点
+func (z *ZSet[M, S]) delete(n *node[M, S]) {
+	var update [maxLevel]*node[M, S]
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.less(n.score, n.member) {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+	for i := 0; i < z.level; i++ {
+		if update[i].levels[i].forward == n {
+			update[i].levels[i].span += n.levels[i].span - 1
+			update[i].levels[i].forward = n.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+	if next := n.levels[0].forward; next != nil {
+		next.backward = n.backward
+	} else {
+		z.tail = n.backward
+	}
+	for z.level > 1 && z.head.levels[z.level-1].forward == nil {
+		z.level--
+	}
+	z.length--
+}
+
+// 更新分数
+// 新分数仍然在前后节点之间时直接修改，否则删除后重新插入
+func (z *ZSet[M, S]) updateScore(n *node[M, S], score S) {
+	prev, next := n.backward, n.levels[0].forward
+	if (prev == nil || prev.less(score, n.member)) && (next == nil || !next.less(score, n.member)) {
+		n.score = score
+		return
+	}
+	z.delete(n)
+	z.dict[n.member] = z.insert(n.member, score)
+}
+
+// 节点的排名，从1开始
+func (z *ZSet[M, S]) rank(n *node[M, S]) int {
+	rank := 0
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && (x.levels[i].forward.less(n.score, n.member) || x.levels[i].forward == n) {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if x == n {
+			return rank
+		}
+	}
+	return 0
+}
+
+// 排名为rank的节点，从1开始
+func (z *ZSet[M, S]) byRank(rank int) *node[M, S] {
+	traversed := 0
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= rank {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// 第一个分数大于等于min的节点
+func (z *ZSet[M, S]) firstGE(min S) *node[M, S] {
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.score < min {
+			x = x.levels[i].forward
+		}
+	}
+	return x.levels[0].forward
+}
+
+// 最后一个分数小于等于max的节点
+func (z *ZSet[M, S]) lastLE(max S) *node[M, S] {
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.score <= max {
+			x = x.levels[i].forward
+		}
+	}
+	if x == z.head {
+		return nil
+	}
+	return x
+}
+
+// 把Redis风格的排名范围转换为[0,length)内的下标
+func (z *ZSet[M, S]) normalize(start, stop int) (int, int, bool) {
+	if start < 0 {
+		start += z.length
+	}
+	if stop < 0 {
+		stop += z.length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= z.length {
+		stop = z.length - 1
+	}
+	if start > stop || start >= z.length {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
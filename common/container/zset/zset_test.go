@@ -0,0 +1,92 @@
+package zset
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestZSet(t *testing.T) {
+	z := New[string, float64]()
+	z.Add("alice", 30)
+	z.Add("bob", 10)
+	z.Add("carol", 20)
+	z.Add("dave", 20)
+	if z.Add("bob", 40) {
+		t.Errorf("Add() existing member = true, want false")
+	}
+	if got := z.Incr("alice", 5); got != 35 {
+		t.Errorf("Incr() = %v, want %v", got, 35)
+	}
+	want := []Entry[string, float64]{{"carol", 20}, {"dave", 20}, {"alice", 35}, {"bob", 40}}
+	if got := z.RangeByRank(0, -1); !reflect.DeepEqual(got, want) {
+		t.Errorf("RangeByRank() = %v, want %v", got, want)
+	}
+	if got := z.RevRangeByRank(0, 1); !reflect.DeepEqual(got, []Entry[string, float64]{{"bob", 40}, {"alice", 35}}) {
+		t.Errorf("RevRangeByRank() = %v", got)
+	}
+	if got := z.RangeByScore(20, 35, 2); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("RangeByScore() = %v, want %v", got, want[:2])
+	}
+	if got := z.RevRangeByScore(35, 0, 0); !reflect.DeepEqual(got, []Entry[string, float64]{{"alice", 35}, {"dave", 20}, {"carol", 20}}) {
+		t.Errorf("RevRangeByScore() = %v", got)
+	}
+	if rank, ok := z.Rank("alice"); rank != 2 || !ok {
+		t.Errorf("Rank() = %v %v, want %v", rank, ok, 2)
+	}
+	if rank, ok := z.RevRank("alice"); rank != 1 || !ok {
+		t.Errorf("RevRank() = %v %v, want %v", rank, ok, 1)
+	}
+	if e, ok := z.PopMin(); e.Member != "carol" || !ok {
+		t.Errorf("PopMin() = %v %v, want %v", e, ok, "carol")
+	}
+	if e, ok := z.PopMax(); e.Member != "bob" || !ok {
+		t.Errorf("PopMax() = %v %v, want %v", e, ok, "bob")
+	}
+	if z.Len() != 2 || z.CountByScore(0, 100) != 2 {
+		t.Errorf("Len() = %v, want %v", z.Len(), 2)
+	}
+}
+
+// 和排序切片对比随机操作的结果
+func TestZSetRandom(t *testing.T) {
+	z := New[int, int]()
+	scores := map[int]int{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		member := r.Intn(500)
+		switch r.Intn(4) {
+		case 0:
+			z.Remove(member)
+			delete(scores, member)
+		case 1:
+			delta := r.Intn(21) - 10
+			z.Incr(member, delta)
+			scores[member] += delta
+		default:
+			score := r.Intn(100)
+			z.Add(member, score)
+			scores[member] = score
+		}
+	}
+
+	var want []Entry[int, int]
+	for m, s := range scores {
+		want = append(want, Entry[int, int]{m, s})
+	}
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Score < want[j].Score || want[i].Score == want[j].Score && want[i].Member < want[j].Member
+	})
+	if got := z.RangeByRank(0, -1); !reflect.DeepEqual(got, want) {
+		t.Fatalf("RangeByRank() len = %v, want %v", len(got), len(want))
+	}
+	for i, e := range want {
+		if rank, _ := z.Rank(e.Member); rank != i {
+			t.Fatalf("Rank(%v) = %v, want %v", e.Member, rank, i)
+		}
+	}
+	if got := len(z.RangeByScore(10, 50, 0)); got != z.CountByScore(10, 50) {
+		t.Errorf("CountByScore() = %v, want %v", z.CountByScore(10, 50), got)
+	}
+}
@@ -0,0 +1,455 @@
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax 字符串不是合法的十进制数
+var ErrSyntax = errors.New("decimal: invalid syntax")
+
+// RoundingMode 舍入模式
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // 四舍五入，0.5远离0
+	RoundHalfEven                     // 银行家舍入，0.5舍入到偶数
+	RoundDown                         // 向0截断
+	RoundUp                           // 远离0进位
+	RoundCeiling                      // 向正无穷
+	RoundFloor                        // 向负无穷
+)
+
+// DivisionScale Div未指定精度时保留的小数位数
+const DivisionScale = 16
+
+// 解析时允许的最大指数，避免很大的指数占用大量内存
+const maxExponent = 1 << 12
+
+// Zero 0
+var Zero = Decimal{}
+
+// GormPrecision、GormScale gorm建表时DECIMAL的总位数和小数位数
+// 超过GormScale位的小数写入时会被数据库舍入，需要保存更多小数位时在初始化时修改，
+// 或者在字段上使用gorm:"type:decimal(p,s)"单独指定
+var (
+	GormPrecision = 20
+	GormScale     = 4
+)
+
+// Decimal 任意精度的十进制定点数
+// 值为 coef * 10^-scale，运算不经过float64，没有二进制舍入误差
+// 加减乘是精确的，结果的小数位数取决于操作数；除法需要指定小数位数和舍入模式
+// 零值表示0，值不可变，可以并发使用
+type Decimal struct {
+	coef  *big.Int // 系数，nil表示0
+	scale int32    // 小数位数，不小于0
+}
+
+// New 创建 value * 10^-scale
+// 例如New(1005, 3)表示1.005
+func New(value int64, scale int32) Decimal {
+	if scale < 0 {
+		panic("scale must be greater than or equal to 0")
+	}
+	return Decimal{coef: big.NewInt(value), scale: scale}
+}
+
+// NewFromInt 从整数创建
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// NewFromFloat 从浮点数创建
+// 使用能还原该浮点数的最短十进制表示，例如1.005得到1.005而不是1.00499999999999989...
+// NaN和无穷大会panic
+func NewFromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		panic("decimal: cannot convert " + strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return d
+}
+
+// Parse 解析字符串
+// 支持可选的正负号、小数点和指数，例如"-1.50"、"+3"、"1.2e-3"
+func Parse(s string) (Decimal, error) {
+	str := s
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil || e > maxExponent || e < -maxExponent {
+			return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+		}
+		exp = e
+		str = str[:i]
+	}
+	neg := false
+	if str != "" && (str[0] == '+' || str[0] == '-') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" {
+		return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+		}
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// MustParse 解析字符串，失败会panic
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Add 加法
+// 结果的小数位数取两者中较大的
+func (d Decimal) Add(d2 Decimal) Decimal {
+	a, b, scale := align(d, d2)
+	return Decimal{coef: a.Add(a, b), scale: scale}
+}
+
+// Sub 减法
+// 结果的小数位数取两者中较大的
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	a, b, scale := align(d, d2)
+	return Decimal{coef: a.Sub(a, b), scale: scale}
+}
+
+// Mul 乘法
+// 结果的小数位数是两者之和
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), d2.int()), scale: d.scale + d2.scale}
+}
+
+// Div 除法
+// 保留DivisionScale位小数，四舍五入；除数为0会panic
+func (d Decimal) Div(d2 Decimal) Decimal {
+	return d.DivRound(d2, DivisionScale, RoundHalfUp)
+}
+
+// DivRound 除法
+// 结果保留scale位小数，按照mode舍入；除数为0会panic
+func (d Decimal) DivRound(d2 Decimal, scale int32, mode RoundingMode) Decimal {
+	if scale < 0 {
+		panic("scale must be greater than or equal to 0")
+	}
+	if d2.Sign() == 0 {
+		panic("decimal: division by zero")
+	}
+	// d/d2 * 10^scale = coef * 10^(scale-d.scale+d2.scale) / coef2
+	num, den := new(big.Int).Set(d.int()), new(big.Int).Set(d2.int())
+	if e := int64(scale) - int64(d.scale) + int64(d2.scale); e >= 0 {
+		num.Mul(num, pow10(e))
+	} else {
+		den.Mul(den, pow10(-e))
+	}
+	return Decimal{coef: quo(num, den, mode), scale: scale}
+}
+
+// Round 舍入到scale位小数
+// 小数位数不足时补0，例如1.5保留2位得到1.50
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale < 0 {
+		panic("scale must be greater than or equal to 0")
+	}
+	if scale >= d.scale {
+		return d.rescale(scale)
+	}
+	return Decimal{coef: quo(new(big.Int).Set(d.int()), pow10(int64(d.scale-scale)), mode), scale: scale}
+}
+
+// Truncate 截断到scale位小数
+func (d Decimal) Truncate(scale int32) Decimal {
+	return d.Round(scale, RoundDown)
+}
+
+// Allocate 按照比例分配金额
+// 结果保留scale位小数，金额先按照RoundHalfUp舍入到scale位，各份之和严格等于舍入后的金额
+// 每份先分到按比例向下取整的金额，除不尽的最小单位按照最大余数法分配：
+// 余数越大的份越先多分一个，余数相同时靠前的份优先，例如100分成3份得到33.34、33.33、33.33
+// 比例不能为负数，且至少有一个大于0
+func (d Decimal) Allocate(scale int32, ratios ...int64) []Decimal {
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			panic("ratios must be greater than or equal to 0")
+		}
+		total.Add(total, big.NewInt(r))
+	}
+	if total.Sign() == 0 {
+		panic("sum of ratios must be greater than 0")
+	}
+
+	amount := d.Round(scale, RoundHalfUp).int()
+	sign := amount.Sign()
+	units := new(big.Int).Abs(amount)
+	parts := make([]Decimal, len(ratios))
+	rems := make([]*big.Int, len(ratios))
+	remain := new(big.Int).Set(units)
+	for i, r := range ratios {
+		share, rem := new(big.Int).QuoRem(new(big.Int).Mul(units, big.NewInt(r)), total, new(big.Int))
+		remain.Sub(remain, share)
+		parts[i] = Decimal{coef: share, scale: scale}
+		rems[i] = rem
+	}
+	// 剩余的最小单位少于份数，分给余数最大的几份
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rems[order[i]].Cmp(rems[order[j]]) > 0
+	})
+	one := big.NewInt(1)
+	for _, i := range order {
+		if remain.Sign() == 0 {
+			break
+		}
+		parts[i].coef.Add(parts[i].coef, one)
+		remain.Sub(remain, one)
+	}
+	if sign < 0 {
+		for i := range parts {
+			parts[i].coef.Neg(parts[i].coef)
+		}
+	}
+	return parts
+}
+
+// Split 把金额平均分成n份
+// 结果保留scale位小数，各份之和严格等于金额
+func (d Decimal) Split(n int, scale int32) []Decimal {
+	if n <= 0 {
+		panic("n must be greater than 0")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return d.Allocate(scale, ratios...)
+}
+
+// Neg 相反数
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs 绝对值
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Cmp 比较大小
+// d < d2返回-1，d == d2返回0，d > d2返回1
+func (d Decimal) Cmp(d2 Decimal) int {
+	a, b, _ := align(d, d2)
+	return a.Cmp(b)
+}
+
+// Equal 数值是否相等
+// 忽略小数位数，例如1.5和1.50相等
+func (d Decimal) Equal(d2 Decimal) bool {
+	return d.Cmp(d2) == 0
+}
+
+// Sign 符号
+// 负数返回-1，0返回0，正数返回1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero 是否为0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Scale 小数位数
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Float64 转换为最接近的浮点数
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String 转换为字符串
+// 保留全部小数位数，例如New(150, 2)得到"1.50"
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(s) <= int(d.scale) {
+			s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// StringFixed 四舍五入到scale位小数后转换为字符串
+func (d Decimal) StringFixed(scale int32) string {
+	return d.Round(scale, RoundHalfUp).String()
+}
+
+// MarshalText 实现encoding.TextMarshaler
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText 实现encoding.TextUnmarshaler
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON 实现json.Marshaler
+// 编码为字符串，避免前端按照浮点数解析丢失精度
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+// 支持字符串和数字，null不修改原值
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// Scan 实现sql.Scanner
+// 支持数据库返回的字符串、字节、整数和浮点数；NULL得到0
+func (d *Decimal) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("decimal: cannot scan %v into Decimal", v)
+		}
+		*d = NewFromFloat(v)
+		return nil
+	}
+	return fmt.Errorf("decimal: cannot scan %T into Decimal", value)
+}
+
+// Value 实现driver.Valuer
+// 以字符串写入数据库，由数据库转换为DECIMAL，不丢失精度
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// GormDataType gorm建表时使用的类型，由GormPrecision和GormScale决定
+// 必须指定精度，只写decimal时MySQL会建成DECIMAL(10,0)，小数部分全部丢失
+func (Decimal) GormDataType() string {
+	return fmt.Sprintf("decimal(%d,%d)", GormPrecision, GormScale)
+}
+
+// 系数，零值返回0
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// 补0到scale位小数，scale不小于d.scale
+func (d Decimal) rescale(scale int32) Decimal {
+	if scale == d.scale {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Mul(d.int(), pow10(int64(scale-d.scale))), scale: scale}
+}
+
+// 对齐小数位数，返回两个新的系数
+func align(d, d2 Decimal) (*big.Int, *big.Int, int32) {
+	scale := d.scale
+	if d2.scale > scale {
+		scale = d2.scale
+	}
+	a := new(big.Int).Set(d.rescale(scale).int())
+	b := new(big.Int).Set(d2.rescale(scale).int())
+	return a, b, scale
+}
+
+// 10的n次方
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// 按照舍入模式计算num/den，会修改num
+func quo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := num.QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// 截断后的商可能是0，余数和被除数同号，用它计算真实的符号
+	sign := r.Sign() * den.Sign()
+
+	var inc bool
+	switch mode {
+	case RoundHalfUp, RoundHalfEven:
+		// 比较余数的2倍和除数
+		twice := new(big.Int).Abs(r)
+		c := twice.Lsh(twice, 1).Cmp(new(big.Int).Abs(den))
+		inc = c > 0 || c == 0 && (mode == RoundHalfUp || q.Bit(0) == 1)
+	case RoundUp:
+		inc = true
+	case RoundCeiling:
+		inc = sign > 0
+	case RoundFloor:
+		inc = sign < 0
+	}
+	if inc {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
@@ -0,0 +1,154 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"1.50", "1.50"},
+		{"-0.05", "-0.05"},
+		{"+3", "3"},
+		{".5", "0.5"},
+		{"1.2e-3", "0.0012"},
+		{"1.2E3", "1200"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).String(); got != tt.want {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "-", ".", "1.2.3", "1e", "abc", "1,5"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) error = nil, want %v", in, ErrSyntax)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustParse("1.005"), MustParse("-0.3")
+	if got := a.Add(b).String(); got != "0.705" {
+		t.Errorf("Add() = %v, want %v", got, "0.705")
+	}
+	if got := a.Sub(b).String(); got != "1.305" {
+		t.Errorf("Sub() = %v, want %v", got, "1.305")
+	}
+	if got := a.Mul(b).String(); got != "-0.3015" {
+		t.Errorf("Mul() = %v, want %v", got, "-0.3015")
+	}
+	if got := NewFromInt(1).DivRound(NewFromInt(3), 4, RoundHalfUp).String(); got != "0.3333" {
+		t.Errorf("DivRound() = %v, want %v", got, "0.3333")
+	}
+	if got := NewFromInt(-2).DivRound(NewFromInt(3), 2, RoundHalfUp).String(); got != "-0.67" {
+		t.Errorf("DivRound() = %v, want %v", got, "-0.67")
+	}
+	if NewFromFloat(0.1).Add(NewFromFloat(0.2)).Cmp(MustParse("0.30")) != 0 {
+		t.Errorf("0.1 + 0.2 != 0.3")
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in   string
+		mode RoundingMode
+		want string
+	}{
+		{"1.005", RoundHalfUp, "1.01"},
+		{"-1.005", RoundHalfUp, "-1.01"},
+		{"1.005", RoundHalfEven, "1.00"},
+		{"1.015", RoundHalfEven, "1.02"},
+		{"1.0051", RoundHalfEven, "1.01"},
+		{"1.009", RoundDown, "1.00"},
+		{"-1.009", RoundDown, "-1.00"},
+		{"1.001", RoundUp, "1.01"},
+		{"-1.001", RoundCeiling, "-1.00"},
+		{"-1.001", RoundFloor, "-1.01"},
+		{"-0.004", RoundFloor, "-0.01"},
+		{"1.5", RoundHalfUp, "1.50"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).Round(2, tt.mode).String(); got != tt.want {
+			t.Errorf("Round(%v, %v) = %v, want %v", tt.in, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts := MustParse("100").Split(3, 2)
+	want := []string{"33.34", "33.33", "33.33"}
+	sum := Zero
+	for i, p := range parts {
+		if p.String() != want[i] {
+			t.Errorf("Split()[%d] = %v, want %v", i, p, want[i])
+		}
+		sum = sum.Add(p)
+	}
+	if !sum.Equal(NewFromInt(100)) {
+		t.Errorf("sum = %v, want %v", sum, 100)
+	}
+
+	// 最大余数法：7*1/6=1余1，7*1/6=1余1，7*4/6=4余4，剩下的0.01给余数最大的第三份
+	parts = MustParse("0.07").Allocate(2, 1, 1, 4)
+	want = []string{"0.01", "0.01", "0.05"}
+	for i, p := range parts {
+		if p.String() != want[i] {
+			t.Errorf("Allocate()[%d] = %v, want %v", i, p, want[i])
+		}
+	}
+
+	parts = MustParse("-0.05").Allocate(2, 0, 3, 7)
+	want = []string{"0.00", "-0.02", "-0.03"}
+	for i, p := range parts {
+		if p.String() != want[i] {
+			t.Errorf("Allocate()[%d] = %v, want %v", i, p, want[i])
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"12.30","b":0.1}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(v)
+	if string(b) != `{"a":"12.30","b":"0.1"}` {
+		t.Errorf("Marshal() = %s", b)
+	}
+
+	var d Decimal
+	if err := d.Scan([]byte("99.99")); err != nil || d.String() != "99.99" {
+		t.Errorf("Scan() = %v %v, want %v", d, err, "99.99")
+	}
+	if v, _ := d.Value(); v != "99.99" {
+		t.Errorf("Value() = %v, want %v", v, "99.99")
+	}
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if err := d.Scan(v); err == nil {
+			t.Errorf("Scan(%v) error = nil, want error", v)
+		}
+	}
+	if err := d.Scan(nil); err != nil || !d.IsZero() {
+		t.Errorf("Scan(nil) = %v %v, want %v", d, err, Zero)
+	}
+}
+
+func TestGormDataType(t *testing.T) {
+	// 默认保留4位小数，更多的小数位会被数据库舍入
+	if got := Zero.GormDataType(); got != "decimal(20,4)" {
+		t.Errorf("GormDataType() = %v, want %v", got, "decimal(20,4)")
+	}
+	defer func(precision, scale int) { GormPrecision, GormScale = precision, scale }(GormPrecision, GormScale)
+	GormPrecision, GormScale = 38, 10
+	if got := Zero.GormDataType(); got != "decimal(38,10)" {
+		t.Errorf("GormDataType() = %v, want %v", got, "decimal(38,10)")
+	}
+}
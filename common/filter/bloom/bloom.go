@@ -1,10 +1,11 @@
 package bloom
 
 import (
-	"hash/fnv"
 	"math"
 	"math/rand"
 	"time"
+
+	"github.com/ahKevinXy/go-web-tools/common/hash"
 )
 
 // uint64的位数
@@ -14,42 +15,34 @@ const uint64Bits = 64
 // https://llimllib.github.io/bloomfilter-tutorial/
 // https://github.com/bits-and-blooms/bloom/blob/master/bloom.go
 type Filter struct {
-	bits   []uint64 // bit数组
-	bitCnt uint64   // bit位数
-	seeds  []uint64 // 哈希种子
+	bits    []uint64 // bit数组
+	bitCnt  uint64   // bit位数
+	hashCnt uint64   // 哈希函数数量
+	seed    uint64   // 哈希种子
 }
 
 func New(capacity uint64, falsePositiveRate float64) *Filter {
-	// bit数量
-	factor := -math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)
-	bitCnt := uint64(math.Ceil(float64(capacity) * factor))
-	// 这里扩大到最后一个uint64大小，避免浪费
-	bitCnt = (bitCnt + uint64Bits - 1) / uint64Bits * uint64Bits
-	// 哈希函数数量
-	seedCnt := int(math.Ceil(math.Ln2 * float64(bitCnt) / float64(capacity)))
-	seeds := make([]uint64, seedCnt)
-	source := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := 0; i < seedCnt; i++ {
-		seeds[i] = source.Uint64()
-	}
+	return newFilter(capacity, falsePositiveRate, newSeed())
+}
+
+func newFilter(capacity uint64, falsePositiveRate float64, seed uint64) *Filter {
+	bitCnt, hashCnt := optimal(capacity, falsePositiveRate)
 	return &Filter{
-		bits:   make([]uint64, bitCnt/uint64Bits),
-		bitCnt: bitCnt,
-		seeds:  seeds,
+		bits:    make([]uint64, bitCnt/uint64Bits),
+		bitCnt:  bitCnt,
+		hashCnt: hashCnt,
+		seed:    seed,
 	}
 }
 
 // Add 添加元素
 func (f *Filter) Add(hash uint64) {
-	for _, seed := range f.seeds {
-		index, offset := f.pos(hash, seed)
-		f.bits[index] |= 1 << offset
-	}
+	f.add(hashUint64(hash, f.seed))
 }
 
 // AddBytes 添加元素
 func (f *Filter) AddBytes(b []byte) {
-	f.Add(f.hash(b))
+	f.add(hashBytes(b, f.seed))
 }
 
 // AddString 添加元素
@@ -61,21 +54,13 @@ func (f *Filter) AddString(s string) {
 // Contains 元素是否存在
 // true表示可能存在
 func (f *Filter) Contains(hash uint64) bool {
-	for _, seed := range f.seeds {
-		index, offset := f.pos(hash, seed)
-		mask := uint64(1) << offset
-		// 判断这一位是否位1
-		if (f.bits[index] & mask) != mask {
-			return false
-		}
-	}
-	return true
+	return f.contains(hashUint64(hash, f.seed))
 }
 
 // ContainsBytes 元素是否存在
 // true表示可能存在
 func (f *Filter) ContainsBytes(b []byte) bool {
-	return f.Contains(f.hash(b))
+	return f.contains(hashBytes(b, f.seed))
 }
 
 // ContainsString 元素是否存在
@@ -84,6 +69,24 @@ func (f *Filter) ContainsString(s string) bool {
 	return f.ContainsBytes([]byte(s))
 }
 
+// Test 元素是否存在
+// 同Contains
+func (f *Filter) Test(hash uint64) bool {
+	return f.Contains(hash)
+}
+
+// TestBytes 元素是否存在
+// 同ContainsBytes
+func (f *Filter) TestBytes(b []byte) bool {
+	return f.ContainsBytes(b)
+}
+
+// TestString 元素是否存在
+// 同ContainsString
+func (f *Filter) TestString(s string) bool {
+	return f.ContainsString(s)
+}
+
 // Clear 清空过滤器
 func (f *Filter) Clear() {
 	for i := range f.bits {
@@ -96,10 +99,48 @@ func (f *Filter) Len() uint64 {
 	return f.bitCnt
 }
 
-// 获取对应元素下标和偏移
-func (f *Filter) pos(h, seed uint64) (uint64, uint64) {
+// 根据元素的两个哈希值添加元素
+func (f *Filter) add(h1, h2 uint64) {
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := location(h1, h2, i, f.bitCnt)
+		f.bits[index] |= 1 << offset
+	}
+}
+
+// 根据元素的两个哈希值判断元素是否存在
+func (f *Filter) contains(h1, h2 uint64) bool {
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := location(h1, h2, i, f.bitCnt)
+		mask := uint64(1) << offset
+		// 判断这一位是否位1
+		if (f.bits[index] & mask) != mask {
+			return false
+		}
+	}
+	return true
+}
+
+// 根据容量和误判率计算bit位数和哈希函数数量
+func optimal(capacity uint64, falsePositiveRate float64) (uint64, uint64) {
+	// bit数量
+	factor := -math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)
+	bitCnt := uint64(math.Ceil(float64(capacity) * factor))
+	// 这里扩大到最后一个uint64大小，避免浪费
+	bitCnt = (bitCnt + uint64Bits - 1) / uint64Bits * uint64Bits
+	// 哈希函数数量
+	hashCnt := uint64(math.Ceil(math.Ln2 * float64(bitCnt) / float64(capacity)))
+	return bitCnt, hashCnt
+}
+
+// 生成哈希种子
+func newSeed() uint64 {
+	return rand.New(rand.NewSource(time.Now().UnixNano())).Uint64()
+}
+
+// 获取第i个哈希函数对应元素下标和偏移
+func location(h1, h2, i, bitCnt uint64) (uint64, uint64) {
 	// 按照位计算的偏移
-	bitsIndex := (h ^ seed) % f.bitCnt
+	bitsIndex := bitsPos(h1, h2, i, bitCnt)
 	// 因为一个元素64位，因此需要转换
 	index := bitsIndex / uint64Bits
 	// 在一个元素里面的偏移
@@ -107,9 +148,25 @@ func (f *Filter) pos(h, seed uint64) (uint64, uint64) {
 	return index, offset
 }
 
-// 计算哈希值
-func (f *Filter) hash(b []byte) uint64 {
-	fnvHash := fnv.New64()
-	fnvHash.Write(b)
-	return fnvHash.Sum64()
+// 第i个哈希函数按照位计算的偏移
+// Kirsch-Mitzenmacher双重哈希 g_i(x) = h1(x) + i*h2(x)
+// 只需要两个独立的哈希值就能模拟k个哈希函数，且渐进误判率不变
+// h2取奇数：bitCnt是64的倍数，奇数步长对bitCnt取模不为0，k个位置不会全部落在同一位上
+// https://www.eecs.harvard.edu/~michaelm/postscripts/rsa2008.pdf
+func bitsPos(h1, h2, i, bitCnt uint64) uint64 {
+	return (h1 + i*(h2|1)) % bitCnt
+}
+
+// 计算字节的两个哈希值
+// 使用128位哈希的高低64位作为双重哈希的两个哈希值
+func hashBytes(b []byte, seed uint64) (uint64, uint64) {
+	return hash.Sum128WithSeed(b, seed)
+}
+
+// 把调用方传入的64位哈希值扩展为两个哈希值
+// 64位哈希值相同的元素无法区分，但只差几位的哈希值会被充分打散
+func hashUint64(h, seed uint64) (uint64, uint64) {
+	h1 := hash.Mix64(h ^ seed)
+	h2 := hash.Mix64(h1 ^ 0x9e3779b97f4a7c15)
+	return h1, h2
 }
@@ -0,0 +1,111 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// 允许实测误判率超出设计误判率的倍数
+const fprTolerance = 1.25
+
+// 统计误判率时查询的元素数量
+const fprProbes = 200000
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	tests := []struct {
+		capacity          uint64
+		falsePositiveRate float64
+	}{
+		{capacity: 1000, falsePositiveRate: 0.01},
+		{capacity: 10000, falsePositiveRate: 0.01},
+		{capacity: 100000, falsePositiveRate: 0.01},
+		{capacity: 10000, falsePositiveRate: 0.001},
+		{capacity: 100000, falsePositiveRate: 0.05},
+	}
+	for _, tt := range tests {
+		name := strconv.FormatUint(tt.capacity, 10) + "_" + strconv.FormatFloat(tt.falsePositiveRate, 'f', -1, 64)
+		t.Run(name+"_string", func(t *testing.T) {
+			f := New(tt.capacity, tt.falsePositiveRate)
+			for i := uint64(0); i < tt.capacity; i++ {
+				f.AddString("key-" + strconv.FormatUint(i, 10))
+			}
+			for i := uint64(0); i < tt.capacity; i++ {
+				if !f.ContainsString("key-" + strconv.FormatUint(i, 10)) {
+					t.Fatalf("ContainsString(%d) = false, want true", i)
+				}
+			}
+			fp := 0
+			for i := uint64(0); i < fprProbes; i++ {
+				if f.ContainsString("probe-" + strconv.FormatUint(i, 10)) {
+					fp++
+				}
+			}
+			checkFalsePositiveRate(t, fp, tt.falsePositiveRate)
+		})
+		// 连续整数的64位哈希值只差几位，检查是否会落在相关的位置上
+		t.Run(name+"_sequential", func(t *testing.T) {
+			f := New(tt.capacity, tt.falsePositiveRate)
+			for i := uint64(0); i < tt.capacity; i++ {
+				f.Add(i)
+			}
+			for i := uint64(0); i < tt.capacity; i++ {
+				if !f.Contains(i) {
+					t.Fatalf("Contains(%d) = false, want true", i)
+				}
+			}
+			fp := 0
+			for i := tt.capacity; i < tt.capacity+fprProbes; i++ {
+				if f.Contains(i) {
+					fp++
+				}
+			}
+			checkFalsePositiveRate(t, fp, tt.falsePositiveRate)
+		})
+		// 只有高位不同的字节
+		t.Run(name+"_high_bits", func(t *testing.T) {
+			f := New(tt.capacity, tt.falsePositiveRate)
+			b := make([]byte, 8)
+			for i := uint64(0); i < tt.capacity; i++ {
+				binary.BigEndian.PutUint64(b, i<<40)
+				f.AddBytes(b)
+			}
+			fp := 0
+			for i := tt.capacity; i < tt.capacity+fprProbes; i++ {
+				binary.BigEndian.PutUint64(b, i<<40)
+				if f.ContainsBytes(b) {
+					fp++
+				}
+			}
+			checkFalsePositiveRate(t, fp, tt.falsePositiveRate)
+		})
+	}
+}
+
+func TestBitsPos(t *testing.T) {
+	// h2是bitCnt的倍数时，k个位置也不能全部相同
+	const bitCnt = 1024
+	for _, h2 := range []uint64{0, bitCnt, 5 * bitCnt} {
+		seen := map[uint64]bool{}
+		for i := uint64(0); i < 7; i++ {
+			seen[bitsPos(3, h2, i, bitCnt)] = true
+		}
+		if len(seen) != 7 {
+			t.Errorf("bitsPos(3, %v, 0..6, %v) has %v distinct positions, want 7", h2, bitCnt, len(seen))
+		}
+	}
+}
+
+// 检查实测误判率没有明显超出设计误判率
+// 误判次数近似服从二项分布，允许tolerance倍误差再加4个标准差
+func checkFalsePositiveRate(t *testing.T, fp int, falsePositiveRate float64) {
+	t.Helper()
+	measured := float64(fp) / fprProbes
+	stddev := math.Sqrt(falsePositiveRate * (1 - falsePositiveRate) / fprProbes)
+	limit := falsePositiveRate*fprTolerance + 4*stddev
+	t.Logf("measured = %.5f, want <= %.5f (falsePositiveRate = %v)", measured, limit, falsePositiveRate)
+	if measured > limit {
+		t.Errorf("measured false positive rate = %v, want <= %v", measured, limit)
+	}
+}
@@ -0,0 +1,145 @@
+package bloom
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AddIfAbsent按照元素哈希值分段加锁的段数，2的幂
+const absentLockCnt = 64
+
+// ConcurrentFilter 并发安全的布隆过滤器
+// 通过对uint64做原子CAS操作设置bit位，多个goroutine可以无锁地添加和查询
+// AddIfAbsent按照元素哈希值分段加锁，同一个元素的并发调用依次执行
+type ConcurrentFilter struct {
+	bits    []uint64                  // bit数组
+	bitCnt  uint64                    // bit位数
+	hashCnt uint64                    // 哈希函数数量
+	seed    uint64                    // 哈希种子
+	locks   [absentLockCnt]sync.Mutex // AddIfAbsent的分段锁
+}
+
+func NewConcurrent(capacity uint64, falsePositiveRate float64) *ConcurrentFilter {
+	bitCnt, hashCnt := optimal(capacity, falsePositiveRate)
+	return &ConcurrentFilter{
+		bits:    make([]uint64, bitCnt/uint64Bits),
+		bitCnt:  bitCnt,
+		hashCnt: hashCnt,
+		seed:    newSeed(),
+	}
+}
+
+// Add 添加元素
+func (f *ConcurrentFilter) Add(hash uint64) {
+	f.add(hashUint64(hash, f.seed))
+}
+
+// AddBytes 添加元素
+func (f *ConcurrentFilter) AddBytes(b []byte) {
+	f.add(hashBytes(b, f.seed))
+}
+
+// AddString 添加元素
+// 字符串类型
+func (f *ConcurrentFilter) AddString(s string) {
+	f.AddBytes([]byte(s))
+}
+
+// AddIfAbsent 元素不存在时添加
+// 返回true表示元素之前一定不存在，本次调用设置了至少一个bit位
+// 返回false表示元素可能已经存在
+// 多个goroutine同时添加同一个新元素时，恰好有一个调用返回true，可以用来对消息ID去重
+func (f *ConcurrentFilter) AddIfAbsent(hash uint64) bool {
+	return f.addIfAbsent(hashUint64(hash, f.seed))
+}
+
+// AddBytesIfAbsent 元素不存在时添加
+func (f *ConcurrentFilter) AddBytesIfAbsent(b []byte) bool {
+	return f.addIfAbsent(hashBytes(b, f.seed))
+}
+
+// AddStringIfAbsent 元素不存在时添加
+// 字符串类型
+func (f *ConcurrentFilter) AddStringIfAbsent(s string) bool {
+	return f.AddBytesIfAbsent([]byte(s))
+}
+
+// Contains 元素是否存在
+// true表示可能存在
+func (f *ConcurrentFilter) Contains(hash uint64) bool {
+	return f.contains(hashUint64(hash, f.seed))
+}
+
+// ContainsBytes 元素是否存在
+// true表示可能存在
+func (f *ConcurrentFilter) ContainsBytes(b []byte) bool {
+	return f.contains(hashBytes(b, f.seed))
+}
+
+// ContainsString 元素是否存在
+// 字符串类型
+func (f *ConcurrentFilter) ContainsString(s string) bool {
+	return f.ContainsBytes([]byte(s))
+}
+
+// Clear 清空过滤器
+// 和Add并发调用时，并发添加的元素可能被部分清除
+func (f *ConcurrentFilter) Clear() {
+	for i := range f.bits {
+		atomic.StoreUint64(&f.bits[i], 0)
+	}
+}
+
+// Len bit位数
+func (f *ConcurrentFilter) Len() uint64 {
+	return f.bitCnt
+}
+
+// 根据元素的两个哈希值，元素不存在时添加
+// 只设置bit位无法区分已经设置的位来自其他元素还是同一个元素的并发调用，
+// 因此同一个元素的调用在同一段锁里串行执行，后执行的会看到所有位都已设置
+func (f *ConcurrentFilter) addIfAbsent(h1, h2 uint64) bool {
+	lock := &f.locks[h1&(absentLockCnt-1)]
+	lock.Lock()
+	defer lock.Unlock()
+	return f.add(h1, h2)
+}
+
+// 根据元素的两个哈希值添加元素
+// 返回true表示本次调用设置了至少一个bit位
+func (f *ConcurrentFilter) add(h1, h2 uint64) bool {
+	added := false
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := location(h1, h2, i, f.bitCnt)
+		if f.setBit(index, uint64(1)<<offset) {
+			added = true
+		}
+	}
+	return added
+}
+
+// 根据元素的两个哈希值判断元素是否存在
+func (f *ConcurrentFilter) contains(h1, h2 uint64) bool {
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := location(h1, h2, i, f.bitCnt)
+		mask := uint64(1) << offset
+		if atomic.LoadUint64(&f.bits[index])&mask == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 通过CAS设置bit位
+// 返回true表示这一位由本次调用从0设置为1
+func (f *ConcurrentFilter) setBit(index, mask uint64) bool {
+	for {
+		old := atomic.LoadUint64(&f.bits[index])
+		if old&mask != 0 {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&f.bits[index], old, old|mask) {
+			return true
+		}
+	}
+}
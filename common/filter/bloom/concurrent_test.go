@@ -0,0 +1,74 @@
+package bloom
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrentFilter(t *testing.T) {
+	const n = 10000
+	f := NewConcurrent(n, 0.01)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < n; i += 8 {
+				f.AddString(strconv.Itoa(i))
+				f.ContainsString(strconv.Itoa(i + n))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if !f.ContainsString(strconv.Itoa(i)) {
+			t.Fatalf("ContainsString(%d) = false, want true", i)
+		}
+	}
+}
+
+func TestConcurrentFilterAddIfAbsent(t *testing.T) {
+	f := NewConcurrent(1000, 0.01)
+	if !f.AddStringIfAbsent("ahKevinXy") {
+		t.Errorf("AddStringIfAbsent() = false, want true")
+	}
+	if f.AddStringIfAbsent("ahKevinXy") {
+		t.Errorf("AddStringIfAbsent() = true, want false")
+	}
+}
+
+func TestConcurrentFilterAddIfAbsentRace(t *testing.T) {
+	const (
+		keys       = 1000
+		goroutines = 16
+	)
+	// 容量远大于元素数量，几乎不会误判
+	f := NewConcurrent(100*keys, 0.0001)
+	for k := 0; k < keys; k++ {
+		key := "key-" + strconv.Itoa(k)
+		var added int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				if f.AddStringIfAbsent(key) {
+					atomic.AddInt32(&added, 1)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+		if added != 1 {
+			t.Fatalf("AddStringIfAbsent(%v) returned true %v times, want 1", key, added)
+		}
+		if !f.ContainsString(key) {
+			t.Fatalf("ContainsString(%v) = false, want true", key)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package bloom
+
+const (
+	counterBits     = 4                        // 每个计数器的位数
+	counterMax      = 1<<counterBits - 1       // 计数器最大值，达到后饱和
+	countersPerWord = uint64Bits / counterBits // 每个uint64存放的计数器数量
+)
+
+// CountingFilter 计数布隆过滤器
+// 每个位置使用4bit计数器代替1bit，因此支持删除元素
+// 计数器达到最大值15后饱和，不再增加也不再减少，避免删除其他元素导致误删
+type CountingFilter struct {
+	counters []uint64 // 计数器数组，每个uint64存放16个计数器
+	bitCnt   uint64   // 计数器数量，和对应Filter的bit位数相同
+	hashCnt  uint64   // 哈希函数数量
+	seed     uint64   // 哈希种子
+}
+
+func NewCounting(capacity uint64, falsePositiveRate float64) *CountingFilter {
+	bitCnt, hashCnt := optimal(capacity, falsePositiveRate)
+	return &CountingFilter{
+		counters: make([]uint64, bitCnt/countersPerWord),
+		bitCnt:   bitCnt,
+		hashCnt:  hashCnt,
+		seed:     newSeed(),
+	}
+}
+
+// Add 添加元素
+func (f *CountingFilter) Add(hash uint64) {
+	f.add(hashUint64(hash, f.seed))
+}
+
+// AddBytes 添加元素
+func (f *CountingFilter) AddBytes(b []byte) {
+	f.add(hashBytes(b, f.seed))
+}
+
+// AddString 添加元素
+// 字符串类型
+func (f *CountingFilter) AddString(s string) {
+	f.AddBytes([]byte(s))
+}
+
+// Remove 删除元素
+// 元素不存在时返回false，不做任何修改
+// 只能删除添加过的元素，否则可能把其他元素误删
+func (f *CountingFilter) Remove(hash uint64) bool {
+	return f.remove(hashUint64(hash, f.seed))
+}
+
+// RemoveBytes 删除元素
+func (f *CountingFilter) RemoveBytes(b []byte) bool {
+	return f.remove(hashBytes(b, f.seed))
+}
+
+// RemoveString 删除元素
+// 字符串类型
+func (f *CountingFilter) RemoveString(s string) bool {
+	return f.RemoveBytes([]byte(s))
+}
+
+// Contains 元素是否存在
+// true表示可能存在
+func (f *CountingFilter) Contains(hash uint64) bool {
+	return f.contains(hashUint64(hash, f.seed))
+}
+
+// ContainsBytes 元素是否存在
+// true表示可能存在
+func (f *CountingFilter) ContainsBytes(b []byte) bool {
+	return f.contains(hashBytes(b, f.seed))
+}
+
+// ContainsString 元素是否存在
+// 字符串类型
+func (f *CountingFilter) ContainsString(s string) bool {
+	return f.ContainsBytes([]byte(s))
+}
+
+// Saturated 已经饱和的计数器数量
+// 饱和的计数器越多，删除后残留的误判越多
+func (f *CountingFilter) Saturated() uint64 {
+	var cnt uint64
+	for index := range f.counters {
+		for offset := uint64(0); offset < countersPerWord; offset++ {
+			if f.counter(uint64(index), offset) == counterMax {
+				cnt++
+			}
+		}
+	}
+	return cnt
+}
+
+// Clear 清空过滤器
+func (f *CountingFilter) Clear() {
+	for i := range f.counters {
+		f.counters[i] = 0
+	}
+}
+
+// Len 计数器数量
+func (f *CountingFilter) Len() uint64 {
+	return f.bitCnt
+}
+
+// ToFilter 转换为普通布隆过滤器
+// 计数器不为0的位置设置为1，返回的过滤器和当前过滤器相互独立，适合只读分发
+func (f *CountingFilter) ToFilter() *Filter {
+	bits := make([]uint64, f.bitCnt/uint64Bits)
+	for index := range f.counters {
+		for offset := uint64(0); offset < countersPerWord; offset++ {
+			if f.counter(uint64(index), offset) == 0 {
+				continue
+			}
+			bitsIndex := uint64(index)*countersPerWord + offset
+			bits[bitsIndex/uint64Bits] |= 1 << (bitsIndex % uint64Bits)
+		}
+	}
+	return &Filter{
+		bits:    bits,
+		bitCnt:  f.bitCnt,
+		hashCnt: f.hashCnt,
+		seed:    f.seed,
+	}
+}
+
+// 根据元素的两个哈希值添加元素
+func (f *CountingFilter) add(h1, h2 uint64) {
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := f.pos(h1, h2, i)
+		if c := f.counter(index, offset); c < counterMax {
+			f.setCounter(index, offset, c+1)
+		}
+	}
+}
+
+// 根据元素的两个哈希值删除元素
+func (f *CountingFilter) remove(h1, h2 uint64) bool {
+	if !f.contains(h1, h2) {
+		return false
+	}
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := f.pos(h1, h2, i)
+		// 饱和的计数器无法知道真实值，不能减少
+		// 多个哈希函数可能落在同一个位置，不能减到0以下
+		if c := f.counter(index, offset); c > 0 && c < counterMax {
+			f.setCounter(index, offset, c-1)
+		}
+	}
+	return true
+}
+
+// 根据元素的两个哈希值判断元素是否存在
+func (f *CountingFilter) contains(h1, h2 uint64) bool {
+	for i := uint64(0); i < f.hashCnt; i++ {
+		index, offset := f.pos(h1, h2, i)
+		if f.counter(index, offset) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 获取第i个哈希函数对应计数器下标和偏移
+func (f *CountingFilter) pos(h1, h2, i uint64) (uint64, uint64) {
+	bitsIndex := bitsPos(h1, h2, i, f.bitCnt)
+	return bitsIndex / countersPerWord, bitsIndex % countersPerWord
+}
+
+// 读取计数器
+func (f *CountingFilter) counter(index, offset uint64) uint64 {
+	return (f.counters[index] >> (offset * counterBits)) & counterMax
+}
+
+// 写入计数器
+func (f *CountingFilter) setCounter(index, offset, c uint64) {
+	shift := offset * counterBits
+	f.counters[index] = f.counters[index]&^(counterMax<<shift) | c<<shift
+}
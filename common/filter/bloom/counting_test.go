@@ -0,0 +1,131 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCountingFilter(t *testing.T) {
+	const n = 1000
+	f := NewCounting(n, 0.01)
+	for i := 0; i < n; i++ {
+		f.AddString("key-" + strconv.Itoa(i))
+	}
+	for i := 0; i < n; i++ {
+		if !f.ContainsString("key-" + strconv.Itoa(i)) {
+			t.Fatalf("ContainsString(%d) = false, want true", i)
+		}
+	}
+
+	// 删除一半，剩下的仍然存在，删除的大部分不再存在
+	for i := 0; i < n; i += 2 {
+		if !f.RemoveString("key-" + strconv.Itoa(i)) {
+			t.Fatalf("RemoveString(%d) = false, want true", i)
+		}
+	}
+	removed := 0
+	for i := 0; i < n; i++ {
+		contains := f.ContainsString("key-" + strconv.Itoa(i))
+		if i%2 == 1 && !contains {
+			t.Fatalf("ContainsString(%d) = false, want true", i)
+		}
+		if i%2 == 0 && !contains {
+			removed++
+		}
+	}
+	if removed < n/2*9/10 {
+		t.Errorf("removed %v keys, want about %v", removed, n/2)
+	}
+
+	if f.RemoveString("absent") {
+		t.Errorf("RemoveString() of absent key = true, want false")
+	}
+
+	f.Clear()
+	if f.ContainsString("key-1") {
+		t.Errorf("ContainsString() after Clear() = true, want false")
+	}
+}
+
+func TestCountingFilterSaturation(t *testing.T) {
+	f := NewCounting(1000, 0.01)
+	for i := 0; i < counterMax+5; i++ {
+		f.AddString("hot")
+	}
+	if f.Saturated() == 0 {
+		t.Fatalf("Saturated() = 0, want greater than 0")
+	}
+	f.AddString("cold")
+	// 饱和的计数器不会减少，删除次数多于添加次数也不会下溢
+	for i := 0; i < 2*counterMax; i++ {
+		f.RemoveString("hot")
+	}
+	if !f.ContainsString("hot") {
+		t.Errorf("ContainsString(hot) = false, want true")
+	}
+	if !f.ContainsString("cold") {
+		t.Errorf("ContainsString(cold) = false, want true")
+	}
+}
+
+func TestCountingFilterCounter(t *testing.T) {
+	f := NewCounting(1000, 0.01)
+	// 同一个uint64里相邻的4bit计数器互不影响
+	f.setCounter(1, 3, counterMax)
+	f.setCounter(1, 4, 7)
+	f.setCounter(1, countersPerWord-1, 1)
+	for offset := uint64(0); offset < countersPerWord; offset++ {
+		want := uint64(0)
+		switch offset {
+		case 3:
+			want = counterMax
+		case 4:
+			want = 7
+		case countersPerWord - 1:
+			want = 1
+		}
+		if got := f.counter(1, offset); got != want {
+			t.Errorf("counter(1, %v) = %v, want %v", offset, got, want)
+		}
+	}
+	if f.counters[0] != 0 || f.counters[2] != 0 {
+		t.Errorf("neighbour words = %x, %x, want 0", f.counters[0], f.counters[2])
+	}
+	f.setCounter(1, 3, 0)
+	if got := f.counter(1, 3); got != 0 {
+		t.Errorf("counter(1, 3) = %v, want 0", got)
+	}
+}
+
+func TestCountingFilterToFilter(t *testing.T) {
+	const n = 1000
+	f := NewCounting(n, 0.01)
+	for i := 0; i < n; i++ {
+		f.AddString("key-" + strconv.Itoa(i))
+	}
+	for i := 0; i < n; i += 3 {
+		f.RemoveString("key-" + strconv.Itoa(i))
+	}
+	b := f.ToFilter()
+	if b.Len() != f.Len() {
+		t.Errorf("Len() = %v, want %v", b.Len(), f.Len())
+	}
+	for i := 0; i < n; i++ {
+		key := "key-" + strconv.Itoa(i)
+		if got, want := b.ContainsString(key), f.ContainsString(key); got != want {
+			t.Errorf("ContainsString(%v) = %v, want %v", key, got, want)
+		}
+	}
+	for i := 0; i < fprProbes/10; i++ {
+		key := "probe-" + strconv.Itoa(i)
+		if got, want := b.ContainsString(key), f.ContainsString(key); got != want {
+			t.Errorf("ContainsString(%v) = %v, want %v", key, got, want)
+		}
+	}
+
+	// 转换后相互独立
+	f.Clear()
+	if !b.ContainsString("key-1") {
+		t.Errorf("ContainsString() after source Clear() = false, want true")
+	}
+}
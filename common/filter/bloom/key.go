@@ -0,0 +1,76 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"reflect"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Key 可以直接添加到过滤器的键类型
+type Key interface {
+	constraints.Integer | ~string | ~[]byte
+}
+
+// AddKey 添加元素
+// 整数按照8字节小端编码后计算哈希值，字符串和字节切片直接计算哈希值
+func AddKey[K Key](f *Filter, key K) {
+	f.AddBytes(keyBytes(key))
+}
+
+// TestKey 元素是否存在
+// true表示可能存在，需要和AddKey配合使用
+func TestKey[K Key](f *Filter, key K) bool {
+	return f.ContainsBytes(keyBytes(key))
+}
+
+// 把键编码为字节
+// AddKey、TestKey和DefaultEncoder共用，保证同一个键在两处的编码相同
+// 不支持的类型会panic，调用前用encodable检查
+func keyBytes(key any) []byte {
+	switch k := key.(type) {
+	case string:
+		return []byte(k)
+	case []byte:
+		return k
+	case int:
+		return uint64Bytes(uint64(k))
+	case int64:
+		return uint64Bytes(uint64(k))
+	case uint64:
+		return uint64Bytes(k)
+	}
+	// 其他整数类型和自定义类型根据底层类型编码
+	v := reflect.ValueOf(key)
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String())
+	case reflect.Slice:
+		return v.Bytes()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64Bytes(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uint64Bytes(v.Uint())
+	}
+	panic("bloom: cannot encode key of type " + v.Type().String())
+}
+
+// 类型是否可以由keyBytes编码
+func encodable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// uint64按照小端编码为字节
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,109 @@
+package bloom
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// ErrIncompatible 两个过滤器的bit位数、哈希函数数量或哈希种子不同，不能合并
+var ErrIncompatible = errors.New("bloom: incompatible filters")
+
+// NewLike 创建一个和f兼容的空过滤器
+// 兼容的过滤器之间才能做Union、Intersect等操作
+func NewLike(f *Filter) *Filter {
+	return &Filter{
+		bits:    make([]uint64, len(f.bits)),
+		bitCnt:  f.bitCnt,
+		hashCnt: f.hashCnt,
+		seed:    f.seed,
+	}
+}
+
+// Clone 复制过滤器
+func (f *Filter) Clone() *Filter {
+	g := NewLike(f)
+	copy(g.bits, f.bits)
+	return g
+}
+
+// Compatible 两个过滤器是否兼容
+// bit位数、哈希函数数量和哈希种子都相同才兼容
+func (f *Filter) Compatible(other *Filter) bool {
+	return f.bitCnt == other.bitCnt && f.hashCnt == other.hashCnt && f.seed == other.seed
+}
+
+// Union 并集
+// 结果保存在f里，之后f包含两个过滤器的所有元素
+func (f *Filter) Union(other *Filter) error {
+	if !f.Compatible(other) {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] |= other.bits[i]
+	}
+	return nil
+}
+
+// Intersect 交集
+// 结果保存在f里，交集的误判率会高于直接添加两个集合共有元素的过滤器
+func (f *Filter) Intersect(other *Filter) error {
+	if !f.Compatible(other) {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] &= other.bits[i]
+	}
+	return nil
+}
+
+// Equal 两个过滤器是否兼容且bit位完全相同
+func (f *Filter) Equal(other *Filter) bool {
+	if !f.Compatible(other) {
+		return false
+	}
+	for i := range f.bits {
+		if f.bits[i] != other.bits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BitsSet 为1的bit位数
+func (f *Filter) BitsSet() uint64 {
+	var cnt uint64
+	for _, word := range f.bits {
+		cnt += uint64(bits.OnesCount64(word))
+	}
+	return cnt
+}
+
+// FillRatio 为1的bit位比例
+func (f *Filter) FillRatio() float64 {
+	return float64(f.BitsSet()) / float64(f.bitCnt)
+}
+
+// EstimatedCount 估计已添加的元素数量
+// Swamidass-Baldi公式 n = -(m/k)ln(1-X/m)，X为1的bit位数
+// 全部bit位为1时无法估计，返回math.MaxUint64
+func (f *Filter) EstimatedCount() uint64 {
+	set := f.BitsSet()
+	if set == f.bitCnt {
+		return math.MaxUint64
+	}
+	m, k := float64(f.bitCnt), float64(f.hashCnt)
+	return uint64(math.Round(-m / k * math.Log(1-float64(set)/m)))
+}
+
+// EstimatedFalsePositiveRate 根据当前填充率估计误判率
+// 一个不存在的元素k个bit位恰好都为1的概率
+func (f *Filter) EstimatedFalsePositiveRate() float64 {
+	return math.Pow(f.FillRatio(), float64(f.hashCnt))
+}
+
+// Saturated 过滤器是否已经饱和
+// 当前估计的误判率超过了设计误判率，说明元素已经超过设计容量，应该重建更大的过滤器
+func (f *Filter) Saturated(falsePositiveRate float64) bool {
+	return f.EstimatedFalsePositiveRate() > falsePositiveRate
+}
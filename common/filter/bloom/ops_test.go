@@ -0,0 +1,55 @@
+package bloom
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestFilterUnion(t *testing.T) {
+	a := New(1000, 0.01)
+	b := NewLike(a)
+	for i := 0; i < 500; i++ {
+		a.AddString(strconv.Itoa(i))
+		b.AddString(strconv.Itoa(i + 500))
+	}
+	if err := a.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !a.ContainsString(strconv.Itoa(i)) {
+			t.Fatalf("ContainsString(%d) = false, want true", i)
+		}
+	}
+	if err := a.Union(New(1000, 0.01)); err != ErrIncompatible {
+		t.Errorf("Union() error = %v, want %v", err, ErrIncompatible)
+	}
+	if !a.Equal(a.Clone()) {
+		t.Errorf("Equal() = false, want true")
+	}
+}
+
+func TestFilterEstimatedCount(t *testing.T) {
+	f := New(10000, 0.01)
+	for i := 0; i < 5000; i++ {
+		AddKey(f, i)
+	}
+	for i := 0; i < 5000; i++ {
+		if !TestKey(f, i) {
+			t.Fatalf("TestKey(%d) = false, want true", i)
+		}
+	}
+	if got := f.EstimatedCount(); math.Abs(float64(got)-5000) > 250 {
+		t.Errorf("EstimatedCount() = %v, want about %v", got, 5000)
+	}
+	if f.Saturated(0.01) {
+		t.Errorf("Saturated() = true, want false")
+	}
+	for i := 5000; i < 30000; i++ {
+		AddKey(f, i)
+	}
+	if !f.Saturated(0.01) {
+		t.Errorf("Saturated() = false, want true")
+	}
+	t.Log(f.FillRatio(), f.EstimatedFalsePositiveRate())
+}
@@ -0,0 +1,169 @@
+package bloom
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RotatingFilter 按时间窗口轮转的布隆过滤器
+// 内部有多代过滤器，每隔interval把最老的一代清空作为最新的一代
+// 添加只写入最新一代，查询检查所有代，因此元素会在window到window+interval之后被自动遗忘
+// 适合对最近一段时间内的消息去重
+// 查询需要检查所有代，误判率约为单代误判率乘以代数
+type RotatingFilter struct {
+	generations []*Filter     // 各代过滤器，环形使用
+	current     int           // 最新一代的下标
+	interval    time.Duration // 轮转间隔
+	seed        uint64        // 哈希种子，所有代共用
+	mutex       sync.RWMutex  // 避免并发问题
+}
+
+// NewRotating 创建按时间窗口轮转的布隆过滤器
+// capacity 每一代的容量，即一个轮转间隔内最多添加的元素数量
+// window 元素至少保留的时间，轮转间隔window/(generations-1)不能为0
+// generations 代数，至少为2，越大内存越多，但元素被遗忘的时间越精确
+func NewRotating(capacity uint64, falsePositiveRate float64, window time.Duration, generations int) *RotatingFilter {
+	if generations < 2 {
+		panic("generations must be greater than 1")
+	}
+	if window <= 0 {
+		panic("window must be greater than 0")
+	}
+	// 轮转间隔为0时Run里的time.NewTicker会panic
+	if window < time.Duration(generations-1) {
+		panic("window must be at least generations-1 nanoseconds")
+	}
+	f := &RotatingFilter{
+		generations: make([]*Filter, generations),
+		interval:    window / time.Duration(generations-1),
+		seed:        newSeed(),
+	}
+	for i := range f.generations {
+		f.generations[i] = newFilter(capacity, falsePositiveRate, f.seed)
+	}
+	return f
+}
+
+// Run 运行轮转
+// 每隔Interval()轮转一次，直到ctx被关闭
+// 也可以不调用Run，而是通过timingwheel等定时器定时调用Rotate
+func (f *RotatingFilter) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.Rotate()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Rotate 轮转
+// 清空最老的一代作为最新的一代
+func (f *RotatingFilter) Rotate() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.current = (f.current + 1) % len(f.generations)
+	f.generations[f.current].Clear()
+}
+
+// Interval 轮转间隔
+func (f *RotatingFilter) Interval() time.Duration {
+	return f.interval
+}
+
+// Add 添加元素
+func (f *RotatingFilter) Add(hash uint64) {
+	f.add(hashUint64(hash, f.seed))
+}
+
+// AddBytes 添加元素
+func (f *RotatingFilter) AddBytes(b []byte) {
+	f.add(hashBytes(b, f.seed))
+}
+
+// AddString 添加元素
+// 字符串类型
+func (f *RotatingFilter) AddString(s string) {
+	f.AddBytes([]byte(s))
+}
+
+// AddIfAbsent 元素不存在时添加
+// 返回true表示元素在窗口内一定没有出现过，可以用于去重
+func (f *RotatingFilter) AddIfAbsent(hash uint64) bool {
+	return f.addIfAbsent(hashUint64(hash, f.seed))
+}
+
+// AddBytesIfAbsent 元素不存在时添加
+func (f *RotatingFilter) AddBytesIfAbsent(b []byte) bool {
+	return f.addIfAbsent(hashBytes(b, f.seed))
+}
+
+// AddStringIfAbsent 元素不存在时添加
+// 字符串类型
+func (f *RotatingFilter) AddStringIfAbsent(s string) bool {
+	return f.AddBytesIfAbsent([]byte(s))
+}
+
+// Contains 元素是否存在
+// true表示可能存在
+func (f *RotatingFilter) Contains(hash uint64) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.contains(hashUint64(hash, f.seed))
+}
+
+// ContainsBytes 元素是否存在
+// true表示可能存在
+func (f *RotatingFilter) ContainsBytes(b []byte) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.contains(hashBytes(b, f.seed))
+}
+
+// ContainsString 元素是否存在
+// 字符串类型
+func (f *RotatingFilter) ContainsString(s string) bool {
+	return f.ContainsBytes([]byte(s))
+}
+
+// Clear 清空过滤器
+func (f *RotatingFilter) Clear() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, g := range f.generations {
+		g.Clear()
+	}
+}
+
+// 根据元素的两个哈希值添加元素
+func (f *RotatingFilter) add(h1, h2 uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.generations[f.current].add(h1, h2)
+}
+
+// 根据元素的两个哈希值添加不存在的元素
+func (f *RotatingFilter) addIfAbsent(h1, h2 uint64) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.contains(h1, h2) {
+		return false
+	}
+	f.generations[f.current].add(h1, h2)
+	return true
+}
+
+// 根据元素的两个哈希值判断元素是否存在
+// 调用方需要持有锁
+func (f *RotatingFilter) contains(h1, h2 uint64) bool {
+	for _, g := range f.generations {
+		if g.contains(h1, h2) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package bloom
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotatingFilter(t *testing.T) {
+	f := NewRotating(1000, 0.01, time.Minute, 3)
+	if f.Interval() != 30*time.Second {
+		t.Errorf("Interval() = %v, want %v", f.Interval(), 30*time.Second)
+	}
+	if !f.AddStringIfAbsent("ahKevinXy") {
+		t.Errorf("AddStringIfAbsent() = false, want true")
+	}
+	if f.AddStringIfAbsent("ahKevinXy") {
+		t.Errorf("AddStringIfAbsent() = true, want false")
+	}
+	// 轮转代数-1次后仍然存在
+	f.Rotate()
+	f.Rotate()
+	if !f.ContainsString("ahKevinXy") {
+		t.Errorf("ContainsString() = false, want true")
+	}
+	// 再轮转一次后被遗忘
+	f.Rotate()
+	if f.ContainsString("ahKevinXy") {
+		t.Errorf("ContainsString() = true, want false")
+	}
+}
+
+func TestNewRotatingZeroInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewRotating() did not panic with zero interval")
+		}
+	}()
+	NewRotating(1000, 0.01, time.Nanosecond, 3)
+}
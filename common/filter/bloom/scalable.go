@@ -0,0 +1,195 @@
+package bloom
+
+import (
+	"math"
+	"unsafe"
+)
+
+const (
+	// DefaultGrowthFactor 默认每个子过滤器容量的增长倍数
+	DefaultGrowthFactor = 2
+	// DefaultTighteningRatio 默认每个子过滤器误判率的收紧比例
+	DefaultTighteningRatio = 0.8
+)
+
+// 可扩展布隆过滤器的子过滤器
+type layer struct {
+	filter   *Filter // 过滤器
+	capacity uint64  // 容量
+	count    uint64  // 已添加元素数量
+}
+
+// ScalableFilter 可扩展布隆过滤器
+// 当前子过滤器装满时，新建一个容量更大、误判率更低的子过滤器
+// 第i个子过滤器误判率为 p*(1-r)*r^i，总误判率不超过p
+// https://gsd.di.uminho.pt/members/cbm/ps/dbloom.pdf
+type ScalableFilter struct {
+	layers          []*layer // 子过滤器
+	capacity        uint64   // 初始容量
+	rate            float64  // 目标误判率
+	growthFactor    uint64   // 容量增长倍数
+	tighteningRatio float64  // 误判率收紧比例
+	seed            uint64   // 哈希种子，所有子过滤器共用，元素的哈希值只需要计算一次
+}
+
+// NewScalable 使用默认增长倍数和收紧比例创建可扩展布隆过滤器
+func NewScalable(capacity uint64, falsePositiveRate float64) *ScalableFilter {
+	return NewScalableWithRatio(capacity, falsePositiveRate, DefaultGrowthFactor, DefaultTighteningRatio)
+}
+
+// NewScalableWithRatio 创建可扩展布隆过滤器
+// growthFactor 每个子过滤器的容量是上一个的几倍，至少为1
+// tighteningRatio 每个子过滤器的误判率是上一个的几倍，范围(0,1)
+func NewScalableWithRatio(capacity uint64, falsePositiveRate float64, growthFactor uint64, tighteningRatio float64) *ScalableFilter {
+	if capacity == 0 {
+		panic("capacity must be greater than 0")
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		panic("falsePositiveRate must be in range (0, 1)")
+	}
+	if growthFactor == 0 {
+		panic("growthFactor must be greater than 0")
+	}
+	if tighteningRatio <= 0 || tighteningRatio >= 1 {
+		panic("tighteningRatio must be in range (0, 1)")
+	}
+	f := &ScalableFilter{
+		capacity:        capacity,
+		rate:            falsePositiveRate,
+		growthFactor:    growthFactor,
+		tighteningRatio: tighteningRatio,
+		seed:            newSeed(),
+	}
+	f.grow()
+	return f
+}
+
+// Add 添加元素
+// 已经存在的元素不会重复计数
+func (f *ScalableFilter) Add(hash uint64) {
+	f.add(hashUint64(hash, f.seed))
+}
+
+// AddBytes 添加元素
+func (f *ScalableFilter) AddBytes(b []byte) {
+	f.add(hashBytes(b, f.seed))
+}
+
+// AddString 添加元素
+// 字符串类型
+func (f *ScalableFilter) AddString(s string) {
+	f.AddBytes([]byte(s))
+}
+
+// Contains 元素是否存在
+// true表示可能存在
+func (f *ScalableFilter) Contains(hash uint64) bool {
+	return f.contains(hashUint64(hash, f.seed))
+}
+
+// ContainsBytes 元素是否存在
+// true表示可能存在
+func (f *ScalableFilter) ContainsBytes(b []byte) bool {
+	return f.contains(hashBytes(b, f.seed))
+}
+
+// ContainsString 元素是否存在
+// 字符串类型
+func (f *ScalableFilter) ContainsString(s string) bool {
+	return f.ContainsBytes([]byte(s))
+}
+
+// Clear 清空过滤器
+// 只保留第一个子过滤器
+func (f *ScalableFilter) Clear() {
+	f.layers[0].filter.Clear()
+	f.layers[0].count = 0
+	f.layers = f.layers[:1]
+}
+
+// Count 已添加元素数量的估计值
+// 被误判为已存在的元素不会计入
+func (f *ScalableFilter) Count() uint64 {
+	var count uint64
+	for _, l := range f.layers {
+		count += l.count
+	}
+	return count
+}
+
+// Capacity 当前所有子过滤器的总容量
+func (f *ScalableFilter) Capacity() uint64 {
+	var capacity uint64
+	for _, l := range f.layers {
+		capacity += l.capacity
+	}
+	return capacity
+}
+
+// Layers 子过滤器数量
+func (f *ScalableFilter) Layers() int {
+	return len(f.layers)
+}
+
+// Len bit位数
+func (f *ScalableFilter) Len() uint64 {
+	var bitCnt uint64
+	for _, l := range f.layers {
+		bitCnt += l.filter.Len()
+	}
+	return bitCnt
+}
+
+// MemoryUsage 占用内存字节数的估计值
+func (f *ScalableFilter) MemoryUsage() uint64 {
+	size := uint64(unsafe.Sizeof(*f))
+	for _, l := range f.layers {
+		size += uint64(unsafe.Sizeof(*l)) + uint64(unsafe.Sizeof(*l.filter))
+		size += uint64(len(l.filter.bits)) * uint64Bits / 8
+	}
+	return size
+}
+
+// 根据元素的两个哈希值添加元素
+func (f *ScalableFilter) add(h1, h2 uint64) {
+	if f.contains(h1, h2) {
+		return
+	}
+	l := f.layers[len(f.layers)-1]
+	if l.count >= l.capacity {
+		l = f.grow()
+	}
+	l.filter.add(h1, h2)
+	l.count++
+}
+
+// 根据元素的两个哈希值判断元素是否存在
+func (f *ScalableFilter) contains(h1, h2 uint64) bool {
+	// 新的子过滤器元素更多，从后往前查
+	for i := len(f.layers) - 1; i >= 0; i-- {
+		if f.layers[i].filter.contains(h1, h2) {
+			return true
+		}
+	}
+	return false
+}
+
+// 新增一个子过滤器
+// 容量超出uint64会panic
+func (f *ScalableFilter) grow() *layer {
+	capacity := f.capacity
+	rate := f.rate * (1 - f.tighteningRatio)
+	for i := 0; i < len(f.layers); i++ {
+		if capacity > math.MaxUint64/f.growthFactor {
+			panic("bloom: scalable filter capacity overflows uint64")
+		}
+		capacity *= f.growthFactor
+		rate *= f.tighteningRatio
+	}
+	l := &layer{
+		filter:   newFilter(capacity, rate, f.seed),
+		capacity: capacity,
+	}
+	f.layers = append(f.layers, l)
+	return l
+}
@@ -0,0 +1,76 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestScalableFilter(t *testing.T) {
+	const (
+		capacity          = 1000
+		falsePositiveRate = 0.01
+		n                 = 20 * capacity
+	)
+	f := NewScalable(capacity, falsePositiveRate)
+	if f.Layers() != 1 || f.Capacity() != capacity {
+		t.Fatalf("Layers() = %v, Capacity() = %v, want 1, %v", f.Layers(), f.Capacity(), capacity)
+	}
+
+	layers, count, capacityBefore, memory := f.Layers(), f.Count(), f.Capacity(), f.MemoryUsage()
+	for i := 0; i < n; i++ {
+		f.AddString("key-" + strconv.Itoa(i))
+		if f.Layers() == layers {
+			continue
+		}
+		// 新增了子过滤器
+		if f.Layers() != layers+1 {
+			t.Fatalf("Layers() = %v, want %v", f.Layers(), layers+1)
+		}
+		if f.Count() <= count || f.Capacity() <= capacityBefore || f.MemoryUsage() <= memory {
+			t.Errorf("Count() = %v, Capacity() = %v, MemoryUsage() = %v, want greater than %v, %v, %v",
+				f.Count(), f.Capacity(), f.MemoryUsage(), count, capacityBefore, memory)
+		}
+		layers, count, capacityBefore, memory = f.Layers(), f.Count(), f.Capacity(), f.MemoryUsage()
+	}
+	// 1000+2000+4000+8000 < 20000 <= 1000+...+16000
+	if f.Layers() != 5 {
+		t.Errorf("Layers() = %v, want %v", f.Layers(), 5)
+	}
+	if f.Count() > n || f.Count() < n*99/100 {
+		t.Errorf("Count() = %v, want about %v", f.Count(), n)
+	}
+
+	for i := 0; i < n; i++ {
+		if !f.ContainsString("key-" + strconv.Itoa(i)) {
+			t.Fatalf("ContainsString(%d) = false, want true", i)
+		}
+	}
+	fp := 0
+	for i := 0; i < fprProbes; i++ {
+		if f.ContainsString("probe-" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	checkFalsePositiveRate(t, fp, falsePositiveRate)
+
+	f.Clear()
+	if f.Layers() != 1 || f.Count() != 0 || f.Capacity() != capacity {
+		t.Errorf("Clear() Layers() = %v, Count() = %v, Capacity() = %v, want 1, 0, %v",
+			f.Layers(), f.Count(), f.Capacity(), capacity)
+	}
+	if f.ContainsString("key-0") {
+		t.Errorf("ContainsString() after Clear() = true, want false")
+	}
+}
+
+func TestScalableFilterGrowOverflow(t *testing.T) {
+	f := NewScalableWithRatio(1, 0.01, 1<<32, 0.5)
+	// 直接补上两层，不用真的添加2^32个元素
+	f.layers = append(f.layers, f.layers[0], f.layers[0])
+	defer func() {
+		if recover() == nil {
+			t.Errorf("grow() did not panic on capacity overflow")
+		}
+	}()
+	f.grow()
+}
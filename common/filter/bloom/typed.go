@@ -0,0 +1,137 @@
+package bloom
+
+import (
+	"encoding"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ahKevinXy/go-web-tools/common/math"
+)
+
+// 批量操作超过这个数量时使用多个goroutine
+const parallelThreshold = 4096
+
+// Encoder 把键编码为字节
+// 相等的键必须编码为相同的字节
+type Encoder[K any] func(key K) []byte
+
+// Typed 带类型的布隆过滤器
+// 通过Encoder把键编码为字节，避免调用方各自转换导致不一致
+// 基于ConcurrentFilter，可以并发使用
+type Typed[K any] struct {
+	f       *ConcurrentFilter
+	encoder Encoder[K]
+}
+
+// NewTyped 创建带类型的布隆过滤器
+// encoder为nil时使用DefaultEncoder
+func NewTyped[K any](capacity uint64, falsePositiveRate float64, encoder Encoder[K]) *Typed[K] {
+	if encoder == nil {
+		encoder = DefaultEncoder[K]()
+	}
+	return &Typed[K]{
+		f:       NewConcurrent(capacity, falsePositiveRate),
+		encoder: encoder,
+	}
+}
+
+// DefaultEncoder 默认编码
+// 实现了encoding.BinaryMarshaler的类型使用MarshalBinary，编码失败会panic
+// 整数、字符串和字节切片和AddKey使用同一个编码：整数按照8字节小端编码，字符串和字节切片直接使用
+// 其他类型没有默认编码，会panic
+func DefaultEncoder[K any]() Encoder[K] {
+	t := reflect.TypeOf((*K)(nil)).Elem()
+	if t.Implements(reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()) {
+		return func(key K) []byte {
+			b, err := any(key).(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				panic(err)
+			}
+			return b
+		}
+	}
+	if encodable(t) {
+		return func(key K) []byte {
+			return keyBytes(key)
+		}
+	}
+	panic("bloom: no default encoder for type " + t.String())
+}
+
+// Add 添加元素
+func (t *Typed[K]) Add(key K) {
+	t.f.AddBytes(t.encoder(key))
+}
+
+// AddIfAbsent 元素不存在时添加
+// 返回true表示元素之前一定不存在
+func (t *Typed[K]) AddIfAbsent(key K) bool {
+	return t.f.AddBytesIfAbsent(t.encoder(key))
+}
+
+// Contains 元素是否存在
+// true表示可能存在
+func (t *Typed[K]) Contains(key K) bool {
+	return t.f.ContainsBytes(t.encoder(key))
+}
+
+// AddAll 批量添加元素
+// 元素较多时分成多份并发添加
+func (t *Typed[K]) AddAll(keys []K) {
+	parallel(len(keys), func(start, end int) {
+		for _, key := range keys[start:end] {
+			t.Add(key)
+		}
+	})
+}
+
+// ContainsAll 是否所有元素都存在
+// 元素较多时分成多份并发查询，有一个元素不存在就提前结束
+func (t *Typed[K]) ContainsAll(keys []K) bool {
+	var missing int32
+	parallel(len(keys), func(start, end int) {
+		for _, key := range keys[start:end] {
+			if atomic.LoadInt32(&missing) != 0 {
+				return
+			}
+			if !t.Contains(key) {
+				atomic.StoreInt32(&missing, 1)
+				return
+			}
+		}
+	})
+	return atomic.LoadInt32(&missing) == 0
+}
+
+// Clear 清空过滤器
+func (t *Typed[K]) Clear() {
+	t.f.Clear()
+}
+
+// Len bit位数
+func (t *Typed[K]) Len() uint64 {
+	return t.f.Len()
+}
+
+// 把[0,n)分成多份并发执行fn
+// n较小时直接在当前goroutine执行
+func parallel(n int, fn func(start, end int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if n < parallelThreshold || workers == 1 {
+		fn(0, n)
+		return
+	}
+	per, _ := math.Split(uint(n), uint(workers))
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += int(per) {
+		end := math.Min(start+int(per), n)
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,71 @@
+package bloom
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type userID string
+
+func TestTyped(t *testing.T) {
+	f := NewTyped[userID](100000, 0.01, nil)
+	keys := make([]userID, 50000)
+	for i := range keys {
+		keys[i] = userID("user-" + strconv.Itoa(i))
+	}
+	f.AddAll(keys)
+	if !f.ContainsAll(keys) {
+		t.Errorf("ContainsAll() = false, want true")
+	}
+	if f.ContainsAll(append(keys, "ahKevinXy")) {
+		t.Errorf("ContainsAll() = true, want false")
+	}
+}
+
+func TestTypedDefaultEncoder(t *testing.T) {
+	ints := NewTyped[int32](1000, 0.01, nil)
+	ints.Add(-1)
+	if !ints.Contains(-1) {
+		t.Errorf("Contains() = false, want true")
+	}
+
+	// time.Time实现了encoding.BinaryMarshaler
+	times := NewTyped[time.Time](1000, 0.01, nil)
+	now := time.Now()
+	if !times.AddIfAbsent(now) {
+		t.Errorf("AddIfAbsent() = false, want true")
+	}
+	if !times.Contains(now) {
+		t.Errorf("Contains() = false, want true")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("DefaultEncoder() did not panic")
+		}
+	}()
+	DefaultEncoder[struct{}]()
+}
+
+func TestDefaultEncoderMatchesKey(t *testing.T) {
+	type id int32
+	type name string
+	checkEncoderMatchesKey(t, "ahKevinXy")
+	checkEncoderMatchesKey(t, []byte("ahKevinXy"))
+	checkEncoderMatchesKey(t, -1)
+	checkEncoderMatchesKey(t, int8(-1))
+	checkEncoderMatchesKey(t, uint16(7))
+	checkEncoderMatchesKey(t, uint64(1<<63))
+	checkEncoderMatchesKey(t, id(-7))
+	checkEncoderMatchesKey(t, name("ahKevinXy"))
+}
+
+// 检查Typed和AddKey对同一个键的编码相同
+func checkEncoderMatchesKey[K Key](t *testing.T, key K) {
+	t.Helper()
+	if got, want := DefaultEncoder[K]()(key), keyBytes(key); !bytes.Equal(got, want) {
+		t.Errorf("DefaultEncoder[%T]() = %x, want %x", key, got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package cms
+
+import (
+	"errors"
+	"math"
+
+	"github.com/ahKevinXy/go-web-tools/common/hash"
+)
+
+// ErrIncompatible 两个Sketch的宽度或深度不同，不能合并
+var ErrIncompatible = errors.New("cms: incompatible sketches")
+
+// Sketch Count-Min Sketch
+// 用depth行width列的计数器估计每个元素出现的次数，估计值只会偏大不会偏小
+// 误差不超过epsilon*Total()的概率至少为1-delta
+// 使用保守更新，只增加必要的计数器，可以明显降低高估
+// 不使用随机种子，宽度和深度相同的Sketch可以跨进程合并
+// 非线程安全，请加锁
+// http://dimacs.rutgers.edu/~graham/pubs/papers/cm-full.pdf
+type Sketch struct {
+	counters [][]uint64 // 计数器
+	width    uint64     // 每行计数器数量
+	depth    uint64     // 行数，即哈希函数数量
+	total    uint64     // 所有元素出现次数之和
+}
+
+// New 根据误差创建
+// epsilon 相对误差，width = ceil(e/epsilon)
+// delta 超出误差的概率，depth = ceil(ln(1/delta))
+func New(epsilon, delta float64) *Sketch {
+	if epsilon <= 0 || epsilon >= 1 {
+		panic("epsilon must be in range (0, 1)")
+	}
+	if delta <= 0 || delta >= 1 {
+		panic("delta must be in range (0, 1)")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := uint64(math.Ceil(math.Log(1 / delta)))
+	return NewWithSize(width, depth)
+}
+
+// NewWithSize 根据宽度和深度创建
+func NewWithSize(width, depth uint64) *Sketch {
+	if width == 0 || depth == 0 {
+		panic("width and depth must be greater than 0")
+	}
+	counters := make([][]uint64, depth)
+	for i := range counters {
+		counters[i] = make([]uint64, width)
+	}
+	return &Sketch{
+		counters: counters,
+		width:    width,
+		depth:    depth,
+	}
+}
+
+// Add 增加元素出现次数
+// 返回增加后的估计次数
+func (s *Sketch) Add(h, count uint64) uint64 {
+	h1, h2 := hashUint64(h)
+	return s.add(h1, h2, count)
+}
+
+// AddBytes 增加元素出现次数
+func (s *Sketch) AddBytes(b []byte, count uint64) uint64 {
+	h1, h2 := hash.Sum128(b)
+	return s.add(h1, h2, count)
+}
+
+// AddString 增加元素出现次数
+// 字符串类型
+func (s *Sketch) AddString(str string, count uint64) uint64 {
+	return s.AddBytes([]byte(str), count)
+}
+
+// Count 估计元素出现次数
+func (s *Sketch) Count(h uint64) uint64 {
+	h1, h2 := hashUint64(h)
+	return s.count(h1, h2)
+}
+
+// CountBytes 估计元素出现次数
+func (s *Sketch) CountBytes(b []byte) uint64 {
+	h1, h2 := hash.Sum128(b)
+	return s.count(h1, h2)
+}
+
+// CountString 估计元素出现次数
+// 字符串类型
+func (s *Sketch) CountString(str string) uint64 {
+	return s.CountBytes([]byte(str))
+}
+
+// Total 所有元素出现次数之和
+func (s *Sketch) Total() uint64 {
+	return s.total
+}
+
+// Merge 合并另一个Sketch
+// 结果保存在s里，对应计数器相加
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.width != other.width || s.depth != other.depth {
+		return ErrIncompatible
+	}
+	for i, row := range other.counters {
+		for j, c := range row {
+			s.counters[i][j] += c
+		}
+	}
+	s.total += other.total
+	return nil
+}
+
+// Decay 所有计数器乘以factor
+// 用于按时间窗口衰减，让最近的元素权重更高
+func (s *Sketch) Decay(factor float64) {
+	if factor < 0 || factor > 1 {
+		panic("factor must be in range [0, 1]")
+	}
+	for _, row := range s.counters {
+		for j := range row {
+			row[j] = uint64(float64(row[j]) * factor)
+		}
+	}
+	s.total = uint64(float64(s.total) * factor)
+}
+
+// Clear 清空
+func (s *Sketch) Clear() {
+	for _, row := range s.counters {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	s.total = 0
+}
+
+// Width 每行计数器数量
+func (s *Sketch) Width() uint64 {
+	return s.width
+}
+
+// Depth 行数
+func (s *Sketch) Depth() uint64 {
+	return s.depth
+}
+
+// 保守更新
+// 新的估计值为当前最小值加count，每行只把小于新估计值的计数器提高到新估计值
+func (s *Sketch) add(h1, h2, count uint64) uint64 {
+	estimate := s.count(h1, h2) + count
+	for i := uint64(0); i < s.depth; i++ {
+		j := s.pos(h1, h2, i)
+		if s.counters[i][j] < estimate {
+			s.counters[i][j] = estimate
+		}
+	}
+	s.total += count
+	return estimate
+}
+
+// 所有行对应计数器的最小值
+func (s *Sketch) count(h1, h2 uint64) uint64 {
+	estimate := uint64(math.MaxUint64)
+	for i := uint64(0); i < s.depth; i++ {
+		if c := s.counters[i][s.pos(h1, h2, i)]; c < estimate {
+			estimate = c
+		}
+	}
+	return estimate
+}
+
+// 第i行对应的计数器下标
+// 双重哈希 g_i(x) = h1(x) + i*h2(x)
+func (s *Sketch) pos(h1, h2, i uint64) uint64 {
+	return (h1 + i*h2) % s.width
+}
+
+// 把调用方传入的64位哈希值扩展为两个哈希值
+func hashUint64(h uint64) (uint64, uint64) {
+	h1 := hash.Mix64(h)
+	h2 := hash.Mix64(h1 ^ 0x9e3779b97f4a7c15)
+	return h1, h2
+}
@@ -0,0 +1,74 @@
+package cms
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestSketch(t *testing.T) {
+	s := New(0.001, 0.01)
+	counts := map[string]uint64{}
+	source := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(source, 1.2, 1, 10000)
+	for i := 0; i < 100000; i++ {
+		key := "ip-" + strconv.FormatUint(zipf.Uint64(), 10)
+		s.AddString(key, 1)
+		counts[key]++
+	}
+	limit := uint64(0.001 * float64(s.Total()))
+	for key, want := range counts {
+		got := s.CountString(key)
+		if got < want || got > want+limit {
+			t.Fatalf("CountString(%s) = %v, want in [%v, %v]", key, got, want, want+limit)
+		}
+	}
+}
+
+func TestTopK(t *testing.T) {
+	a := NewTopK(3, 0.001, 0.01)
+	b := NewTopK(3, 0.001, 0.01)
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 10; j++ {
+			key := "tenant-" + strconv.Itoa(j)
+			// 出现次数随j递增
+			a.Add(key, uint64(j))
+			b.Add(key, uint64(j))
+		}
+	}
+	want := []string{"tenant-9", "tenant-8", "tenant-7"}
+	checkTopK(t, a.List(), want)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	checkTopK(t, a.List(), want)
+	if a.Count("tenant-9") != 1800 {
+		t.Errorf("Count() = %v, want %v", a.Count("tenant-9"), 1800)
+	}
+
+	a.Decay(0.5)
+	if a.Count("tenant-9") != 900 {
+		t.Errorf("Count() = %v, want %v", a.Count("tenant-9"), 900)
+	}
+	// 衰减后新的热点可以进入
+	a.Add("tenant-new", 10000)
+	checkTopK(t, a.List(), []string{"tenant-new", "tenant-9", "tenant-8"})
+
+	a.Reset()
+	if a.Len() != 0 {
+		t.Errorf("Len() = %v, want %v", a.Len(), 0)
+	}
+}
+
+func checkTopK(t *testing.T, items []Item, want []string) {
+	t.Helper()
+	if len(items) != len(want) {
+		t.Fatalf("List() = %v, want %v", items, want)
+	}
+	for i := range items {
+		if items[i].Key != want[i] {
+			t.Fatalf("List() = %v, want %v", items, want)
+		}
+	}
+}
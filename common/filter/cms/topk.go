@@ -0,0 +1,147 @@
+package cms
+
+import (
+	"sort"
+
+	"github.com/ahKevinXy/go-web-tools/common/container/meap"
+)
+
+// Item 热点元素
+type Item struct {
+	Key   string // 元素
+	Count uint64 // 估计出现次数
+}
+
+// TopK 热点元素统计
+// 用Count-Min Sketch估计每个元素的出现次数，用小顶堆保存出现次数最多的k个元素
+// 内存固定，适合统计请求最多的IP、接口、租户等
+// 非线程安全，请加锁
+type TopK struct {
+	k      int                        // 保留的元素数量
+	sketch *Sketch                    // 出现次数估计
+	heap   *meap.Meap[string, uint64] // 出现次数最多的k个元素，堆顶是其中最少的
+}
+
+// NewTopK 创建
+// epsilon和delta的含义同New
+func NewTopK(k int, epsilon, delta float64) *TopK {
+	return NewTopKWithSketch(k, New(epsilon, delta))
+}
+
+// NewTopKWithSketch 使用指定的Sketch创建
+func NewTopKWithSketch(k int, sketch *Sketch) *TopK {
+	if k <= 0 {
+		panic("k must be greater than 0")
+	}
+	return &TopK{
+		k:      k,
+		sketch: sketch,
+		heap:   newMinHeap(),
+	}
+}
+
+// Add 增加元素出现次数
+// 返回元素是否在TopK里
+func (t *TopK) Add(key string, count uint64) bool {
+	return t.offer(key, t.sketch.AddString(key, count))
+}
+
+// Count 估计元素出现次数
+func (t *TopK) Count(key string) uint64 {
+	return t.sketch.CountString(key)
+}
+
+// Contains 元素是否在TopK里
+func (t *TopK) Contains(key string) bool {
+	return t.heap.Contains(key)
+}
+
+// List 出现次数最多的k个元素，按照出现次数从大到小排序
+func (t *TopK) List() []Item {
+	items := t.items()
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count == items[j].Count {
+			return items[i].Key < items[j].Key
+		}
+		return items[i].Count > items[j].Count
+	})
+	return items
+}
+
+// Len TopK里的元素数量
+func (t *TopK) Len() int {
+	return t.heap.Len()
+}
+
+// Sketch 内部的Count-Min Sketch
+func (t *TopK) Sketch() *Sketch {
+	return t.sketch
+}
+
+// Decay 所有出现次数乘以factor
+// 每个时间窗口结束时调用，让旧的热点逐渐退出
+func (t *TopK) Decay(factor float64) {
+	t.sketch.Decay(factor)
+	items := t.items()
+	t.heap = newMinHeap()
+	for _, item := range items {
+		t.heap.Push(item.Key, t.sketch.CountString(item.Key))
+	}
+}
+
+// Reset 清空
+// 每个时间窗口结束时调用，只统计当前窗口
+func (t *TopK) Reset() {
+	t.sketch.Clear()
+	t.heap = newMinHeap()
+}
+
+// Merge 合并另一个TopK
+// 合并Sketch后，用合并后的估计值从两边的热点元素中重新选出k个
+func (t *TopK) Merge(other *TopK) error {
+	if err := t.sketch.Merge(other.sketch); err != nil {
+		return err
+	}
+	candidates := append(t.items(), other.items()...)
+	t.heap = newMinHeap()
+	for _, item := range candidates {
+		t.offer(item.Key, t.sketch.CountString(item.Key))
+	}
+	return nil
+}
+
+// 根据估计次数决定元素是否进入TopK
+func (t *TopK) offer(key string, estimate uint64) bool {
+	// 已经在堆里或者堆没满，直接更新
+	if t.heap.Contains(key) || t.heap.Len() < t.k {
+		t.heap.Push(key, estimate)
+		return true
+	}
+	// 比堆顶大，替换堆顶
+	if estimate > t.heap.Peek().Value {
+		t.heap.Pop()
+		t.heap.Push(key, estimate)
+		return true
+	}
+	return false
+}
+
+// 取出堆里所有元素，不改变堆
+func (t *TopK) items() []Item {
+	items := make([]Item, 0, t.heap.Len())
+	for !t.heap.Empty() {
+		e := t.heap.Pop()
+		items = append(items, Item{Key: e.Key, Count: e.Value})
+	}
+	for _, item := range items {
+		t.heap.Push(item.Key, item.Count)
+	}
+	return items
+}
+
+// 按照出现次数排序的小顶堆
+func newMinHeap() *meap.Meap[string, uint64] {
+	return meap.New(func(e1, e2 meap.Entry[string, uint64]) bool {
+		return e1.Value < e2.Value
+	})
+}
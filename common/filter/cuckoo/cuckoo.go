@@ -0,0 +1,391 @@
+package cuckoo
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/ahKevinXy/go-web-tools/common/math"
+)
+
+const (
+	// DefaultFingerprintBits 默认指纹位数
+	DefaultFingerprintBits = 16
+	// DefaultBucketSize 默认每个桶的槽位数
+	DefaultBucketSize = 4
+	// DefaultMaxKicks 默认插入时最多踢出的次数
+	DefaultMaxKicks = 500
+
+	minFingerprintBits = 4
+	maxFingerprintBits = 32
+	maxBucketSize      = 8
+	uint64Bits         = 64
+	// 目标装载率，根据它计算桶数量
+	targetLoadFactor = 0.95
+	// 序列化版本
+	encodingVersion = 1
+	// 序列化头部长度
+	headerSize = 1 + 1 + 1 + 4 + 8 + 8 + 1 + 8 + 4
+)
+
+// ErrInvalidData 序列化数据无效
+var ErrInvalidData = errors.New("cuckoo: invalid data")
+
+// 被踢出但没有位置存放的指纹
+type victim struct {
+	index       uint64 // 所在桶下标
+	fingerprint uint32 // 指纹
+	used        bool   // 是否有值
+}
+
+// Filter 布谷鸟过滤器
+// 每个元素只保存一个指纹，存放在两个候选桶之一，支持删除
+// 低误判率时比布隆过滤器更省空间
+// 非线程安全，请加锁
+// https://www.cs.cmu.edu/~dga/papers/cuckoo-conext2014.pdf
+type Filter struct {
+	slots           []uint64 // 指纹按位紧凑存放
+	fingerprintBits uint     // 指纹位数
+	bucketSize      uint     // 每个桶的槽位数
+	bucketCnt       uint64   // 桶数量，2的幂
+	maxKicks        uint     // 最多踢出次数
+	count           uint64   // 元素数量
+	victim          victim   // 插入失败时暂存的指纹
+	source          *rand.Rand
+}
+
+// New 使用默认配置创建布谷鸟过滤器
+func New(capacity uint64) *Filter {
+	return NewWithConfig(capacity, DefaultFingerprintBits, DefaultBucketSize, DefaultMaxKicks)
+}
+
+// NewWithConfig 创建布谷鸟过滤器
+// fingerprintBits 指纹位数，范围[4,32]，越大误判率越低，误判率约为 2*bucketSize/2^fingerprintBits
+// bucketSize 每个桶的槽位数，范围[1,8]
+// maxKicks 插入时最多踢出的次数，超过后认为过滤器已满
+func NewWithConfig(capacity uint64, fingerprintBits, bucketSize, maxKicks uint) *Filter {
+	if capacity == 0 {
+		panic("capacity must be greater than 0")
+	}
+	if fingerprintBits < minFingerprintBits || fingerprintBits > maxFingerprintBits {
+		panic("fingerprintBits must be in range [4, 32]")
+	}
+	if bucketSize == 0 || bucketSize > maxBucketSize {
+		panic("bucketSize must be in range [1, 8]")
+	}
+	bucketCnt := uint64(float64(capacity)/targetLoadFactor)/uint64(bucketSize) + 1
+	bucketCnt = math.RoundUpPowOf2(bucketCnt)
+	f := &Filter{
+		fingerprintBits: fingerprintBits,
+		bucketSize:      bucketSize,
+		bucketCnt:       bucketCnt,
+		maxKicks:        maxKicks,
+		source:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	f.slots = make([]uint64, f.words())
+	return f
+}
+
+// Insert 插入元素
+// 返回false表示过滤器已满
+// 同一个元素可以插入多次，删除时也需要删除多次
+func (f *Filter) Insert(hash uint64) bool {
+	// 有被踢出的指纹说明已经满了
+	if f.victim.used {
+		return false
+	}
+	i1, i2, fp := f.indexes(hash)
+	if f.insertToBucket(i1, fp) || f.insertToBucket(i2, fp) {
+		f.count++
+		return true
+	}
+	// 两个桶都满了，随机踢出一个指纹到它的另一个桶
+	index := i1
+	if f.source.Intn(2) == 1 {
+		index = i2
+	}
+	for kick := uint(0); kick < f.maxKicks; kick++ {
+		slot := uint(f.source.Intn(int(f.bucketSize)))
+		old := f.fingerprint(index, slot)
+		f.setFingerprint(index, slot, fp)
+		fp = old
+		index = f.altIndex(index, fp)
+		if f.insertToBucket(index, fp) {
+			f.count++
+			return true
+		}
+	}
+	// 踢出的指纹没有位置了，暂存起来，保证已插入的元素不会丢失
+	f.victim = victim{index: index, fingerprint: fp, used: true}
+	f.count++
+	return true
+}
+
+// InsertBytes 插入元素
+func (f *Filter) InsertBytes(b []byte) bool {
+	return f.Insert(sum64(b))
+}
+
+// InsertString 插入元素
+// 字符串类型
+func (f *Filter) InsertString(s string) bool {
+	return f.InsertBytes([]byte(s))
+}
+
+// Lookup 元素是否存在
+// true表示可能存在
+func (f *Filter) Lookup(hash uint64) bool {
+	i1, i2, fp := f.indexes(hash)
+	if f.victim.used && f.victim.fingerprint == fp &&
+		(f.victim.index == i1 || f.victim.index == i2) {
+		return true
+	}
+	return f.findInBucket(i1, fp) >= 0 || f.findInBucket(i2, fp) >= 0
+}
+
+// LookupBytes 元素是否存在
+// true表示可能存在
+func (f *Filter) LookupBytes(b []byte) bool {
+	return f.Lookup(sum64(b))
+}
+
+// LookupString 元素是否存在
+// 字符串类型
+func (f *Filter) LookupString(s string) bool {
+	return f.LookupBytes([]byte(s))
+}
+
+// Delete 删除元素
+// 元素不存在时返回false
+// 只能删除插入过的元素，否则可能把指纹相同的其他元素误删
+func (f *Filter) Delete(hash uint64) bool {
+	i1, i2, fp := f.indexes(hash)
+	if f.victim.used && f.victim.fingerprint == fp &&
+		(f.victim.index == i1 || f.victim.index == i2) {
+		f.victim = victim{}
+		f.count--
+		return true
+	}
+	if !f.deleteFromBucket(i1, fp) && !f.deleteFromBucket(i2, fp) {
+		return false
+	}
+	f.count--
+	// 腾出了位置，尝试放回暂存的指纹
+	if f.victim.used {
+		v := f.victim
+		f.victim = victim{}
+		f.count--
+		f.insertFingerprint(v.index, v.fingerprint)
+	}
+	return true
+}
+
+// DeleteBytes 删除元素
+func (f *Filter) DeleteBytes(b []byte) bool {
+	return f.Delete(sum64(b))
+}
+
+// DeleteString 删除元素
+// 字符串类型
+func (f *Filter) DeleteString(s string) bool {
+	return f.DeleteBytes([]byte(s))
+}
+
+// Count 元素数量
+func (f *Filter) Count() uint64 {
+	return f.count
+}
+
+// Cap 槽位总数
+func (f *Filter) Cap() uint64 {
+	return f.bucketCnt * uint64(f.bucketSize)
+}
+
+// LoadFactor 装载率
+func (f *Filter) LoadFactor() float64 {
+	return float64(f.count) / float64(f.Cap())
+}
+
+// Clear 清空过滤器
+func (f *Filter) Clear() {
+	for i := range f.slots {
+		f.slots[i] = 0
+	}
+	f.count = 0
+	f.victim = victim{}
+}
+
+// MarshalBinary 序列化
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, headerSize+len(f.slots)*8)
+	data[0] = encodingVersion
+	data[1] = byte(f.fingerprintBits)
+	data[2] = byte(f.bucketSize)
+	binary.LittleEndian.PutUint32(data[3:], uint32(f.maxKicks))
+	binary.LittleEndian.PutUint64(data[7:], f.bucketCnt)
+	binary.LittleEndian.PutUint64(data[15:], f.count)
+	if f.victim.used {
+		data[23] = 1
+	}
+	binary.LittleEndian.PutUint64(data[24:], f.victim.index)
+	binary.LittleEndian.PutUint32(data[32:], f.victim.fingerprint)
+	for i, word := range f.slots {
+		binary.LittleEndian.PutUint64(data[headerSize+i*8:], word)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 反序列化
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || data[0] != encodingVersion {
+		return ErrInvalidData
+	}
+	g := &Filter{
+		fingerprintBits: uint(data[1]),
+		bucketSize:      uint(data[2]),
+		maxKicks:        uint(binary.LittleEndian.Uint32(data[3:])),
+		bucketCnt:       binary.LittleEndian.Uint64(data[7:]),
+		count:           binary.LittleEndian.Uint64(data[15:]),
+		victim: victim{
+			index:       binary.LittleEndian.Uint64(data[24:]),
+			fingerprint: binary.LittleEndian.Uint32(data[32:]),
+			used:        data[23] == 1,
+		},
+	}
+	if g.fingerprintBits < minFingerprintBits || g.fingerprintBits > maxFingerprintBits ||
+		g.bucketSize == 0 || g.bucketSize > maxBucketSize || !math.IsPowOf2(g.bucketCnt) {
+		return ErrInvalidData
+	}
+	// 暂存的指纹之后会被放回桶里，下标越界会panic
+	if g.victim.used && (g.victim.index >= g.bucketCnt || g.victim.fingerprint == 0 ||
+		g.victim.fingerprint > 1<<g.fingerprintBits-1) {
+		return ErrInvalidData
+	}
+	// 伪造的桶数量可能使words()溢出，计算出的长度恰好和数据一致
+	if _, ok := math.MulChecked(g.bucketCnt, uint64(g.bucketSize)*uint64(g.fingerprintBits)); !ok {
+		return ErrInvalidData
+	}
+	words := g.words()
+	if uint64(len(data)-headerSize) != words*8 {
+		return ErrInvalidData
+	}
+	g.slots = make([]uint64, words)
+	for i := range g.slots {
+		g.slots[i] = binary.LittleEndian.Uint64(data[headerSize+i*8:])
+	}
+	g.source = rand.New(rand.NewSource(time.Now().UnixNano()))
+	*f = *g
+	return nil
+}
+
+// 存放所有指纹需要的uint64数量
+func (f *Filter) words() uint64 {
+	return (f.Cap()*uint64(f.fingerprintBits) + uint64Bits - 1) / uint64Bits
+}
+
+// 计算两个候选桶下标和指纹
+func (f *Filter) indexes(hash uint64) (uint64, uint64, uint32) {
+	hash = mix(hash)
+	// 高32位计算指纹，0表示空槽位，因此指纹不能为0
+	fp := uint32(hash>>32) & (1<<f.fingerprintBits - 1)
+	if fp == 0 {
+		fp = 1
+	}
+	i1 := hash & (f.bucketCnt - 1)
+	return i1, f.altIndex(i1, fp), fp
+}
+
+// 另一个候选桶下标
+// 两个桶下标互为 i ^ hash(fp)，因此只根据指纹和当前桶就能计算另一个桶
+func (f *Filter) altIndex(index uint64, fp uint32) uint64 {
+	return (index ^ mix(uint64(fp))) & (f.bucketCnt - 1)
+}
+
+// 把指纹插入桶的空槽位
+func (f *Filter) insertToBucket(index uint64, fp uint32) bool {
+	for slot := uint(0); slot < f.bucketSize; slot++ {
+		if f.fingerprint(index, slot) == 0 {
+			f.setFingerprint(index, slot, fp)
+			return true
+		}
+	}
+	return false
+}
+
+// 根据一个候选桶插入指纹
+func (f *Filter) insertFingerprint(index uint64, fp uint32) {
+	if f.insertToBucket(index, fp) {
+		f.count++
+		return
+	}
+	if f.insertToBucket(f.altIndex(index, fp), fp) {
+		f.count++
+		return
+	}
+	f.victim = victim{index: index, fingerprint: fp, used: true}
+	f.count++
+}
+
+// 从桶里删除一个指纹
+func (f *Filter) deleteFromBucket(index uint64, fp uint32) bool {
+	slot := f.findInBucket(index, fp)
+	if slot < 0 {
+		return false
+	}
+	f.setFingerprint(index, uint(slot), 0)
+	return true
+}
+
+// 查找指纹在桶里的槽位，不存在返回-1
+func (f *Filter) findInBucket(index uint64, fp uint32) int {
+	for slot := uint(0); slot < f.bucketSize; slot++ {
+		if f.fingerprint(index, slot) == fp {
+			return int(slot)
+		}
+	}
+	return -1
+}
+
+// 读取槽位上的指纹
+func (f *Filter) fingerprint(index uint64, slot uint) uint32 {
+	pos := (index*uint64(f.bucketSize) + uint64(slot)) * uint64(f.fingerprintBits)
+	word, offset := pos/uint64Bits, pos%uint64Bits
+	v := f.slots[word] >> offset
+	// 跨越了两个uint64
+	if offset+uint64(f.fingerprintBits) > uint64Bits {
+		v |= f.slots[word+1] << (uint64Bits - offset)
+	}
+	return uint32(v & (1<<f.fingerprintBits - 1))
+}
+
+// 写入槽位上的指纹
+func (f *Filter) setFingerprint(index uint64, slot uint, fp uint32) {
+	pos := (index*uint64(f.bucketSize) + uint64(slot)) * uint64(f.fingerprintBits)
+	word, offset := pos/uint64Bits, pos%uint64Bits
+	mask := uint64(1)<<f.fingerprintBits - 1
+	f.slots[word] = f.slots[word]&^(mask<<offset) | uint64(fp)<<offset
+	// 跨越了两个uint64
+	if offset+uint64(f.fingerprintBits) > uint64Bits {
+		shift := uint64Bits - offset
+		f.slots[word+1] = f.slots[word+1]&^(mask>>shift) | uint64(fp)>>shift
+	}
+}
+
+// 打散哈希值的各个位
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// 计算哈希值
+func sum64(b []byte) uint64 {
+	fnvHash := fnv.New64()
+	fnvHash.Write(b)
+	return fnvHash.Sum64()
+}
@@ -0,0 +1,96 @@
+package cuckoo
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	const n = 10000
+	f := New(n)
+	for i := 0; i < n; i++ {
+		if !f.InsertString(strconv.Itoa(i)) {
+			t.Fatalf("InsertString(%d) = false, want true", i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if !f.LookupString(strconv.Itoa(i)) {
+			t.Fatalf("LookupString(%d) = false, want true", i)
+		}
+	}
+	for i := 0; i < n/2; i++ {
+		if !f.DeleteString(strconv.Itoa(i)) {
+			t.Fatalf("DeleteString(%d) = false, want true", i)
+		}
+	}
+	for i := n / 2; i < n; i++ {
+		if !f.LookupString(strconv.Itoa(i)) {
+			t.Fatalf("LookupString(%d) = false, want true", i)
+		}
+	}
+	if f.Count() != n/2 {
+		t.Errorf("Count() = %v, want %v", f.Count(), n/2)
+	}
+	t.Log(f.LoadFactor())
+}
+
+func TestFilterFull(t *testing.T) {
+	f := NewWithConfig(100, 8, 2, 50)
+	inserted := 0
+	for i := 0; i < 1000; i++ {
+		if f.InsertString(strconv.Itoa(i)) {
+			inserted++
+		}
+	}
+	for i := 0; i < inserted; i++ {
+		if !f.LookupString(strconv.Itoa(i)) {
+			t.Fatalf("LookupString(%d) = false, want true", i)
+		}
+	}
+	if uint64(inserted) > f.Cap()+1 {
+		t.Errorf("inserted = %v, want <= %v", inserted, f.Cap()+1)
+	}
+}
+
+func TestFilterMarshalBinary(t *testing.T) {
+	f := NewWithConfig(1000, 12, 4, DefaultMaxKicks)
+	for i := 0; i < 1000; i++ {
+		f.InsertString(strconv.Itoa(i))
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Filter{}
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !g.LookupString(strconv.Itoa(i)) {
+			t.Fatalf("LookupString(%d) = false, want true", i)
+		}
+	}
+	if g.Count() != f.Count() {
+		t.Errorf("Count() = %v, want %v", g.Count(), f.Count())
+	}
+	if err := g.UnmarshalBinary(data[:len(data)-1]); err != ErrInvalidData {
+		t.Errorf("UnmarshalBinary() error = %v, want %v", err, ErrInvalidData)
+	}
+
+	// 暂存指纹的桶下标越界
+	bad := append([]byte(nil), data...)
+	bad[23] = 1
+	binary.LittleEndian.PutUint64(bad[24:], f.bucketCnt)
+	binary.LittleEndian.PutUint32(bad[32:], 1)
+	if err := g.UnmarshalBinary(bad); err != ErrInvalidData {
+		t.Errorf("UnmarshalBinary() with victim out of range error = %v, want %v", err, ErrInvalidData)
+	}
+	// 2^61个桶*8个槽位*32位溢出为0，和没有槽位数据的长度一致
+	bad = make([]byte, headerSize)
+	bad[0], bad[1], bad[2] = encodingVersion, 32, 8
+	binary.LittleEndian.PutUint64(bad[7:], 1<<61)
+	if err := g.UnmarshalBinary(bad); err != ErrInvalidData {
+		t.Errorf("UnmarshalBinary() with overflowing size error = %v, want %v", err, ErrInvalidData)
+	}
+}
@@ -0,0 +1,423 @@
+package fuse
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+	"unsafe"
+
+	"github.com/ahKevinXy/go-web-tools/common/hash"
+)
+
+const (
+	// 每个元素对应的位置数量
+	arity = 3
+	// 最大段长度
+	maxSegmentLength = 1 << 18
+	// 构建时最多重试的次数
+	maxIterations = 100
+	// 序列化版本
+	encodingVersion = 1
+	// 序列化头部长度
+	headerSize = 1 + 1 + 8 + 4 + 4 + 4
+)
+
+var (
+	// ErrBuildFailed 多次更换种子后仍然构建失败
+	// 元素哈希值大量重复时可能出现
+	ErrBuildFailed = errors.New("fuse: build failed")
+	// ErrInvalidData 序列化数据无效
+	ErrInvalidData = errors.New("fuse: invalid data")
+)
+
+// Fingerprint 指纹类型
+// uint8误判率约为1/256，uint16误判率约为1/65536
+type Fingerprint interface {
+	~uint8 | ~uint16
+}
+
+// Filter 二进制熔断过滤器（Binary Fuse Filter）
+// 根据一组固定的元素一次性构建，之后只能查询，不能添加和删除
+// 每个元素占用约1.13倍指纹大小的空间，比相同误判率的布隆过滤器节省约20%以上，查询只需要访问3个位置
+// 构建后只读，可以被多个goroutine无锁并发查询
+// https://arxiv.org/abs/2201.01174
+type Filter[T Fingerprint] struct {
+	seed               uint64 // 哈希种子
+	segmentLength      uint32 // 段长度，2的幂
+	segmentLengthMask  uint32 // 段长度掩码
+	segmentCount       uint32 // 段数量
+	segmentCountLength uint32 // 段数量*段长度
+	fingerprints       []T    // 指纹数组
+}
+
+// New 根据元素的哈希值构建
+// 重复的哈希值会被去重
+func New[T Fingerprint](keys []uint64) (*Filter[T], error) {
+	f := &Filter[T]{}
+	if err := f.populate(keys); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewFromBytes 根据元素构建
+func NewFromBytes[T Fingerprint](keys [][]byte) (*Filter[T], error) {
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		hashes[i] = sum64(key)
+	}
+	return New[T](hashes)
+}
+
+// NewFromStrings 根据元素构建
+// 字符串类型
+func NewFromStrings[T Fingerprint](keys []string) (*Filter[T], error) {
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		hashes[i] = sum64([]byte(key))
+	}
+	return New[T](hashes)
+}
+
+// Contains 元素是否存在
+// true表示可能存在
+func (f *Filter[T]) Contains(key uint64) bool {
+	h := mixSplit(key, f.seed)
+	fp := T(fingerprint(h))
+	h0, h1, h2 := f.positions(h)
+	return fp^f.fingerprints[h0]^f.fingerprints[h1]^f.fingerprints[h2] == 0
+}
+
+// ContainsBytes 元素是否存在
+// true表示可能存在
+func (f *Filter[T]) ContainsBytes(b []byte) bool {
+	return f.Contains(sum64(b))
+}
+
+// ContainsString 元素是否存在
+// 字符串类型
+func (f *Filter[T]) ContainsString(s string) bool {
+	return f.ContainsBytes([]byte(s))
+}
+
+// Len 指纹数量
+func (f *Filter[T]) Len() int {
+	return len(f.fingerprints)
+}
+
+// SizeInBytes 指纹数组占用的字节数
+func (f *Filter[T]) SizeInBytes() int {
+	var fp T
+	return len(f.fingerprints) * int(unsafe.Sizeof(fp))
+}
+
+// MarshalBinary 序列化
+func (f *Filter[T]) MarshalBinary() ([]byte, error) {
+	var fp T
+	size := int(unsafe.Sizeof(fp))
+	data := make([]byte, headerSize+len(f.fingerprints)*size)
+	data[0] = encodingVersion
+	data[1] = byte(size)
+	binary.LittleEndian.PutUint64(data[2:], f.seed)
+	binary.LittleEndian.PutUint32(data[10:], f.segmentLength)
+	binary.LittleEndian.PutUint32(data[14:], f.segmentCount)
+	binary.LittleEndian.PutUint32(data[18:], uint32(len(f.fingerprints)))
+	fps := data[headerSize:]
+	for i, v := range f.fingerprints {
+		if size == 1 {
+			fps[i] = byte(v)
+		} else {
+			binary.LittleEndian.PutUint16(fps[i*2:], uint16(v))
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 反序列化
+func (f *Filter[T]) UnmarshalBinary(data []byte) error {
+	var fp T
+	size := int(unsafe.Sizeof(fp))
+	if len(data) < headerSize || data[0] != encodingVersion || int(data[1]) != size {
+		return ErrInvalidData
+	}
+	g := &Filter[T]{
+		seed:          binary.LittleEndian.Uint64(data[2:]),
+		segmentLength: binary.LittleEndian.Uint32(data[10:]),
+		segmentCount:  binary.LittleEndian.Uint32(data[14:]),
+	}
+	length := uint64(binary.LittleEndian.Uint32(data[18:]))
+	if g.segmentLength == 0 || g.segmentLength > maxSegmentLength || g.segmentLength&(g.segmentLength-1) != 0 ||
+		g.segmentCount == 0 || length != (uint64(g.segmentCount)+arity-1)*uint64(g.segmentLength) ||
+		uint64(len(data)-headerSize) != length*uint64(size) {
+		return ErrInvalidData
+	}
+	g.segmentLengthMask = g.segmentLength - 1
+	g.segmentCountLength = g.segmentCount * g.segmentLength
+	g.fingerprints = make([]T, length)
+	fps := data[headerSize:]
+	for i := range g.fingerprints {
+		if size == 1 {
+			g.fingerprints[i] = T(fps[i])
+		} else {
+			g.fingerprints[i] = T(binary.LittleEndian.Uint16(fps[i*2:]))
+		}
+	}
+	*f = *g
+	return nil
+}
+
+// 根据元素数量计算段长度、段数量和数组长度
+func (f *Filter[T]) init(size uint32) {
+	f.segmentLength = segmentLength(size)
+	f.segmentLengthMask = f.segmentLength - 1
+	var capacity uint32
+	if size > 1 {
+		capacity = uint32(math.Round(float64(size) * sizeFactor(size)))
+	}
+	// 段数量至少为1，数组长度为(段数量+arity-1)*段长度
+	segmentCount := (capacity + f.segmentLength - 1) / f.segmentLength
+	if segmentCount <= arity-1 {
+		segmentCount = 1
+	} else {
+		segmentCount -= arity - 1
+	}
+	f.segmentCount = segmentCount
+	f.segmentCountLength = segmentCount * f.segmentLength
+	f.fingerprints = make([]T, (segmentCount+arity-1)*f.segmentLength)
+}
+
+// 构建
+// 把每个元素映射到3个相邻段中的位置，不断剥离只被一个元素占用的位置，
+// 再按剥离的逆序设置指纹，使得3个位置的指纹异或等于元素的指纹
+func (f *Filter[T]) populate(keys []uint64) error {
+	size := uint32(len(keys))
+	f.init(size)
+	capacity := uint32(len(f.fingerprints))
+
+	rng := uint64(1)
+	f.seed = splitMix64(&rng)
+
+	alone := make([]uint32, capacity)
+	// 低2位为占用这个位置的元素在其3个位置中的序号的异或，高位为占用数量
+	t2count := make([]uint8, capacity)
+	// 占用这个位置的元素哈希值的异或
+	t2hash := make([]uint64, capacity)
+	reverseH := make([]uint8, size)
+	reverseOrder := make([]uint64, size+1)
+	reverseOrder[size] = 1
+
+	// 按照哈希值高位分块，提高缓存命中率
+	blockBits := 1
+	for uint32(1)<<blockBits < f.segmentCount {
+		blockBits++
+	}
+	startPos := make([]uint64, 1<<blockBits)
+	var h012 [5]uint32
+	pruned := false
+
+	for iterations := 0; ; iterations++ {
+		if iterations >= maxIterations {
+			return ErrBuildFailed
+		}
+		for i := range startPos {
+			startPos[i] = uint64(i) * uint64(size) >> blockBits
+		}
+		for _, key := range keys {
+			h := mixSplit(key, f.seed)
+			segment := h >> (64 - blockBits)
+			for reverseOrder[startPos[segment]] != 0 {
+				segment = (segment + 1) & (1<<blockBits - 1)
+			}
+			reverseOrder[startPos[segment]] = h
+			startPos[segment]++
+		}
+
+		failed := false
+		duplicates := uint32(0)
+		for i := uint32(0); i < size; i++ {
+			h := reverseOrder[i]
+			i0, i1, i2 := f.positions(h)
+			t2count[i0] += 4
+			t2hash[i0] ^= h
+			t2count[i1] += 4
+			t2count[i1] ^= 1
+			t2hash[i1] ^= h
+			t2count[i2] += 4
+			t2count[i2] ^= 2
+			t2hash[i2] ^= h
+			// 哈希值重复时，某个位置会恰好被同一个哈希值占用两次，撤销这次添加
+			if t2hash[i0]&t2hash[i1]&t2hash[i2] == 0 {
+				if (t2hash[i0] == 0 && t2count[i0] == 8) ||
+					(t2hash[i1] == 0 && t2count[i1] == 8) ||
+					(t2hash[i2] == 0 && t2count[i2] == 8) {
+					duplicates++
+					t2count[i0] -= 4
+					t2hash[i0] ^= h
+					t2count[i1] -= 4
+					t2count[i1] ^= 1
+					t2hash[i1] ^= h
+					t2count[i2] -= 4
+					t2count[i2] ^= 2
+					t2hash[i2] ^= h
+				}
+			}
+			// 计数器溢出
+			if t2count[i0] < 4 || t2count[i1] < 4 || t2count[i2] < 4 {
+				failed = true
+			}
+		}
+
+		if !failed {
+			// 只被一个元素占用的位置入队
+			queueSize := uint32(0)
+			for i := uint32(0); i < capacity; i++ {
+				alone[queueSize] = i
+				if t2count[i]>>2 == 1 {
+					queueSize++
+				}
+			}
+			stackSize := uint32(0)
+			for queueSize > 0 {
+				queueSize--
+				index := alone[queueSize]
+				if t2count[index]>>2 != 1 {
+					continue
+				}
+				h := t2hash[index]
+				found := t2count[index] & 3
+				reverseH[stackSize] = found
+				reverseOrder[stackSize] = h
+				stackSize++
+
+				// 从另外两个位置剥离这个元素
+				i0, i1, i2 := f.positions(h)
+				h012[1], h012[2], h012[3], h012[4] = i1, i2, i0, i1
+
+				other := h012[found+1]
+				alone[queueSize] = other
+				if t2count[other]>>2 == 2 {
+					queueSize++
+				}
+				t2count[other] -= 4
+				t2count[other] ^= mod3(found + 1)
+				t2hash[other] ^= h
+
+				other = h012[found+2]
+				alone[queueSize] = other
+				if t2count[other]>>2 == 2 {
+					queueSize++
+				}
+				t2count[other] -= 4
+				t2count[other] ^= mod3(found + 2)
+				t2hash[other] ^= h
+			}
+			if stackSize+duplicates == size {
+				size = stackSize
+				break
+			}
+			// 没能剥离所有重复元素，去重后重试
+			if duplicates > 0 && !pruned {
+				keys = dedup(keys)
+				size = uint32(len(keys))
+				pruned = true
+			}
+		}
+
+		// 更换种子重试
+		for i := range reverseOrder {
+			reverseOrder[i] = 0
+		}
+		reverseOrder[size] = 1
+		for i := range t2count {
+			t2count[i] = 0
+			t2hash[i] = 0
+		}
+		f.seed = splitMix64(&rng)
+	}
+
+	// 按照剥离的逆序设置指纹
+	for i := int(size) - 1; i >= 0; i-- {
+		h := reverseOrder[i]
+		fp := T(fingerprint(h))
+		i0, i1, i2 := f.positions(h)
+		found := reverseH[i]
+		h012[0], h012[1], h012[2], h012[3], h012[4] = i0, i1, i2, i0, i1
+		f.fingerprints[h012[found]] = fp ^ f.fingerprints[h012[found+1]] ^ f.fingerprints[h012[found+2]]
+	}
+	return nil
+}
+
+// 元素对应的3个位置，分别位于3个相邻的段中
+func (f *Filter[T]) positions(h uint64) (uint32, uint32, uint32) {
+	hi, _ := bits.Mul64(h, uint64(f.segmentCountLength))
+	h0 := uint32(hi)
+	h1 := h0 + f.segmentLength
+	h2 := h1 + f.segmentLength
+	h1 ^= uint32(h>>18) & f.segmentLengthMask
+	h2 ^= uint32(h) & f.segmentLengthMask
+	return h0, h1, h2
+}
+
+// 段长度
+func segmentLength(size uint32) uint32 {
+	if size == 0 {
+		return 4
+	}
+	length := uint32(1) << int(math.Floor(math.Log(float64(size))/math.Log(3.33)+2.25))
+	if length > maxSegmentLength {
+		length = maxSegmentLength
+	}
+	return length
+}
+
+// 数组长度相对于元素数量的倍数
+func sizeFactor(size uint32) float64 {
+	return math.Max(1.125, 0.875+0.25*math.Log(1000000)/math.Log(float64(size)))
+}
+
+// 排序去重
+func dedup(keys []uint64) []uint64 {
+	keys = append([]uint64(nil), keys...)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	n := 0
+	for i, key := range keys {
+		if i == 0 || key != keys[n-1] {
+			keys[n] = key
+			n++
+		}
+	}
+	return keys[:n]
+}
+
+func mod3(x uint8) uint8 {
+	if x > 2 {
+		x -= 3
+	}
+	return x
+}
+
+func fingerprint(h uint64) uint64 {
+	return h ^ (h >> 32)
+}
+
+func mixSplit(key, seed uint64) uint64 {
+	return hash.Mix64(key + seed)
+}
+
+func splitMix64(seed *uint64) uint64 {
+	*seed += 0x9e3779b97f4a7c15
+	z := *seed
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// 计算哈希值
+func sum64(b []byte) uint64 {
+	h, _ := hash.Sum128(b)
+	return h
+}
@@ -0,0 +1,91 @@
+package fuse
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 1000, 100000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			keys := make([]string, n)
+			for i := range keys {
+				keys[i] = "key-" + strconv.Itoa(i)
+			}
+			f8, err := NewFromStrings[uint8](keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f16, err := NewFromStrings[uint16](keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range keys {
+				if !f8.ContainsString(key) || !f16.ContainsString(key) {
+					t.Fatalf("ContainsString(%s) = false, want true", key)
+				}
+			}
+			fp8, fp16 := 0, 0
+			const probes = 100000
+			for i := 0; i < probes; i++ {
+				key := "probe-" + strconv.Itoa(i)
+				if f8.ContainsString(key) {
+					fp8++
+				}
+				if f16.ContainsString(key) {
+					fp16++
+				}
+			}
+			if rate := float64(fp8) / probes; rate > 0.006 {
+				t.Errorf("uint8 false positive rate = %v, want <= %v", rate, 0.006)
+			}
+			if rate := float64(fp16) / probes; rate > 0.0001 {
+				t.Errorf("uint16 false positive rate = %v, want <= %v", rate, 0.0001)
+			}
+		})
+	}
+}
+
+func TestFilterDuplicates(t *testing.T) {
+	keys := make([]uint64, 0, 20000)
+	for i := uint64(0); i < 10000; i++ {
+		keys = append(keys, i, i)
+	}
+	f, err := New[uint8](keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 10000; i++ {
+		if !f.Contains(i) {
+			t.Fatalf("Contains(%d) = false, want true", i)
+		}
+	}
+}
+
+func TestFilterMarshalBinary(t *testing.T) {
+	keys := make([]uint64, 10000)
+	for i := range keys {
+		keys[i] = uint64(i)
+	}
+	f, err := New[uint16](keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Filter[uint16]{}
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if !g.Contains(key) {
+			t.Fatalf("Contains(%d) = false, want true", key)
+		}
+	}
+	if err := (&Filter[uint8]{}).UnmarshalBinary(data); err != ErrInvalidData {
+		t.Errorf("UnmarshalBinary() error = %v, want %v", err, ErrInvalidData)
+	}
+	t.Log(f.SizeInBytes())
+}
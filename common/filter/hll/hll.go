@@ -0,0 +1,358 @@
+package hll
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/ahKevinXy/go-web-tools/common/hash"
+)
+
+const (
+	// MinPrecision 最小精度
+	MinPrecision = 4
+	// MaxPrecision 最大精度
+	MaxPrecision = 18
+	// DefaultPrecision 默认精度，2^14个寄存器，标准误差约0.81%
+	DefaultPrecision = 14
+
+	// 稀疏表示使用的精度
+	sparsePrecision = 25
+	// 稀疏表示中rho值占用的位数
+	sparseRhoBits = 6
+	// 序列化版本
+	encodingVersion = 1
+	// 序列化时的表示方式
+	encodingSparse = 0
+	encodingDense  = 1
+)
+
+var (
+	// ErrPrecisionMismatch 精度不同，不能合并
+	ErrPrecisionMismatch = errors.New("hll: precision mismatch")
+	// ErrInvalidData 序列化数据无效
+	ErrInvalidData = errors.New("hll: invalid data")
+)
+
+// Sketch HyperLogLog++基数估计
+// 元素较少时使用稀疏表示，只保存出现过的寄存器，精度更高也更省内存
+// 稀疏表示的元素超过寄存器数量的1/4时转换为稠密表示
+// 估计使用Ertl的改进算法，不需要HLL++的经验偏差修正表，在整个基数范围内都是无偏的
+// 非线程安全，请加锁
+// https://research.google/pubs/pub40671/
+// https://arxiv.org/abs/1702.01284
+type Sketch struct {
+	p         uint8            // 精度
+	sparse    map[uint32]uint8 // 稀疏表示，精度为25的寄存器下标到rho的映射
+	registers []uint8          // 稠密表示，为nil时表示使用稀疏表示
+}
+
+// New 使用默认精度创建
+func New() *Sketch {
+	return NewWithPrecision(DefaultPrecision)
+}
+
+// NewWithPrecision 创建
+// precision 精度，范围[4,18]，寄存器数量为2^precision，标准误差约为1.04/sqrt(2^precision)
+func NewWithPrecision(precision uint8) *Sketch {
+	if precision < MinPrecision || precision > MaxPrecision {
+		panic("precision must be in range [4, 18]")
+	}
+	return &Sketch{
+		p:      precision,
+		sparse: map[uint32]uint8{},
+	}
+}
+
+// Add 添加元素
+// hash会再经过一次打散，因此可以直接传入整数ID
+func (s *Sketch) Add(h uint64) {
+	s.insert(hash.Mix64(h))
+}
+
+// AddBytes 添加元素
+// 使用和进程无关的哈希，不同进程产生的Sketch可以合并
+func (s *Sketch) AddBytes(b []byte) {
+	h, _ := hash.Sum128(b)
+	s.Add(h)
+}
+
+// AddString 添加元素
+// 字符串类型
+func (s *Sketch) AddString(str string) {
+	s.AddBytes([]byte(str))
+}
+
+// Count 估计不同元素的数量
+func (s *Sketch) Count() uint64 {
+	var histogram []uint32
+	var m uint64
+	if s.registers == nil {
+		// 稀疏表示相当于精度为25的稠密表示，大部分寄存器为0
+		m = 1 << sparsePrecision
+		histogram = make([]uint32, 64-sparsePrecision+2)
+		histogram[0] = uint32(m - uint64(len(s.sparse)))
+		for _, rho := range s.sparse {
+			histogram[rho]++
+		}
+	} else {
+		m = 1 << s.p
+		histogram = make([]uint32, 64-int(s.p)+2)
+		for _, rho := range s.registers {
+			histogram[rho]++
+		}
+	}
+	return uint64(math.Round(estimate(m, histogram)))
+}
+
+// Merge 合并另一个Sketch
+// 结果保存在s里，相当于两个Sketch添加过的元素的并集
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.p != other.p {
+		return ErrPrecisionMismatch
+	}
+	if other.registers == nil {
+		for index, rho := range other.sparse {
+			s.insertSparse(index, rho)
+		}
+		s.maybeToDense()
+		return nil
+	}
+	s.toDense()
+	for i, rho := range other.registers {
+		if rho > s.registers[i] {
+			s.registers[i] = rho
+		}
+	}
+	return nil
+}
+
+// Clone 复制
+func (s *Sketch) Clone() *Sketch {
+	c := &Sketch{p: s.p}
+	if s.registers != nil {
+		c.registers = append([]uint8(nil), s.registers...)
+		return c
+	}
+	c.sparse = make(map[uint32]uint8, len(s.sparse))
+	for index, rho := range s.sparse {
+		c.sparse[index] = rho
+	}
+	return c
+}
+
+// Clear 清空
+// 恢复为稀疏表示
+func (s *Sketch) Clear() {
+	s.sparse = map[uint32]uint8{}
+	s.registers = nil
+}
+
+// Precision 精度
+func (s *Sketch) Precision() uint8 {
+	return s.p
+}
+
+// Sparse 是否是稀疏表示
+func (s *Sketch) Sparse() bool {
+	return s.registers == nil
+}
+
+// MarshalBinary 序列化
+// 稀疏表示按照下标排序后差分编码为变长整数
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	if s.registers != nil {
+		data := make([]byte, 3, 3+len(s.registers))
+		data[0], data[1], data[2] = encodingVersion, s.p, encodingDense
+		return append(data, s.registers...), nil
+	}
+	entries := make([]uint32, 0, len(s.sparse))
+	for index, rho := range s.sparse {
+		entries = append(entries, index<<sparseRhoBits|uint32(rho))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i] < entries[j]
+	})
+	data := make([]byte, 3+binary.MaxVarintLen32*(len(entries)+1))
+	data[0], data[1], data[2] = encodingVersion, s.p, encodingSparse
+	n := 3 + binary.PutUvarint(data[3:], uint64(len(entries)))
+	var prev uint32
+	for _, entry := range entries {
+		n += binary.PutUvarint(data[n:], uint64(entry-prev))
+		prev = entry
+	}
+	return data[:n], nil
+}
+
+// UnmarshalBinary 反序列化
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 || data[0] != encodingVersion || data[1] < MinPrecision || data[1] > MaxPrecision {
+		return ErrInvalidData
+	}
+	p := data[1]
+	switch data[2] {
+	case encodingDense:
+		registers := data[3:]
+		if len(registers) != 1<<p {
+			return ErrInvalidData
+		}
+		for _, rho := range registers {
+			if rho > 64-p+1 {
+				return ErrInvalidData
+			}
+		}
+		*s = Sketch{p: p, registers: append([]uint8(nil), registers...)}
+		return nil
+	case encodingSparse:
+		data = data[3:]
+		n, read := binary.Uvarint(data)
+		// 每个元素至少占一个字节
+		if read <= 0 || n > uint64(len(data)-read) {
+			return ErrInvalidData
+		}
+		data = data[read:]
+		sparse := make(map[uint32]uint8, n)
+		var entry uint64
+		for i := uint64(0); i < n; i++ {
+			delta, read := binary.Uvarint(data)
+			if read <= 0 {
+				return ErrInvalidData
+			}
+			data = data[read:]
+			entry += delta
+			rho := uint8(entry & (1<<sparseRhoBits - 1))
+			if entry >= 1<<(sparsePrecision+sparseRhoBits) || rho == 0 || rho > 64-sparsePrecision+1 {
+				return ErrInvalidData
+			}
+			sparse[uint32(entry>>sparseRhoBits)] = rho
+		}
+		if len(data) != 0 {
+			return ErrInvalidData
+		}
+		*s = Sketch{p: p, sparse: sparse}
+		return nil
+	default:
+		return ErrInvalidData
+	}
+}
+
+// 根据打散后的哈希值更新寄存器
+func (s *Sketch) insert(h uint64) {
+	if s.registers != nil {
+		index, rho := split(h, s.p)
+		if rho > s.registers[index] {
+			s.registers[index] = rho
+		}
+		return
+	}
+	index, rho := split(h, sparsePrecision)
+	s.insertSparse(index, rho)
+	s.maybeToDense()
+}
+
+// 更新稀疏表示的寄存器
+// 已经是稠密表示时先转换下标
+func (s *Sketch) insertSparse(index uint32, rho uint8) {
+	if s.registers != nil {
+		index, rho = s.denseRegister(index, rho)
+		if rho > s.registers[index] {
+			s.registers[index] = rho
+		}
+		return
+	}
+	if rho > s.sparse[index] {
+		s.sparse[index] = rho
+	}
+}
+
+// 稀疏表示超过阈值时转换为稠密表示
+func (s *Sketch) maybeToDense() {
+	if s.registers == nil && len(s.sparse) > 1<<s.p/4 {
+		s.toDense()
+	}
+}
+
+// 转换为稠密表示
+func (s *Sketch) toDense() {
+	if s.registers != nil {
+		return
+	}
+	s.registers = make([]uint8, 1<<s.p)
+	for index, rho := range s.sparse {
+		index, rho := s.denseRegister(index, rho)
+		if rho > s.registers[index] {
+			s.registers[index] = rho
+		}
+	}
+	s.sparse = nil
+}
+
+// 把精度为25的寄存器下标和rho转换为当前精度
+func (s *Sketch) denseRegister(index uint32, rho uint8) (uint32, uint8) {
+	shift := sparsePrecision - s.p
+	// 稀疏下标多出来的低位就是稠密表示中剩余位的最高几位
+	low := index & (1<<shift - 1)
+	index >>= shift
+	if low != 0 {
+		return index, shift - uint8(bits.Len32(low)) + 1
+	}
+	return index, shift + rho
+}
+
+// 哈希值的高p位作为寄存器下标，剩余位的前导0数量+1作为rho
+func split(h uint64, p uint8) (uint32, uint8) {
+	index := uint32(h >> (64 - p))
+	rho := uint8(bits.LeadingZeros64(h<<p)) + 1
+	// 剩余位全为0
+	if limit := 64 - p + 1; rho > limit {
+		rho = limit
+	}
+	return index, rho
+}
+
+// Ertl改进的基数估计
+// histogram[k]表示rho为k的寄存器数量，长度为q+2，q为剩余位数
+func estimate(m uint64, histogram []uint32) float64 {
+	q := len(histogram) - 2
+	fm := float64(m)
+	z := fm * tau(1-float64(histogram[q+1])/fm)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + float64(histogram[k]))
+	}
+	z += fm * sigma(float64(histogram[0])/fm)
+	return fm * fm / (2 * math.Ln2 * z)
+}
+
+func sigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+func tau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
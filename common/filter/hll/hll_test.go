@@ -0,0 +1,84 @@
+package hll
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestSketchCount(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 1000, 10000, 100000, 1000000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			s := New()
+			for i := 0; i < n; i++ {
+				s.AddString("user-" + strconv.Itoa(i))
+				// 重复元素不影响结果
+				s.AddString("user-" + strconv.Itoa(i/2))
+			}
+			checkCount(t, s, n)
+		})
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	a, b := New(), New()
+	for i := 0; i < 60000; i++ {
+		a.Add(uint64(i))
+		b.Add(uint64(i + 30000))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	checkCount(t, a, 90000)
+
+	// 稀疏表示合并
+	c, d := New(), New()
+	for i := 0; i < 1000; i++ {
+		c.Add(uint64(i))
+		d.Add(uint64(i + 500))
+	}
+	if err := c.Merge(d); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Sparse() {
+		t.Errorf("Sparse() = false, want true")
+	}
+	checkCount(t, c, 1500)
+
+	if err := c.Merge(NewWithPrecision(10)); err != ErrPrecisionMismatch {
+		t.Errorf("Merge() error = %v, want %v", err, ErrPrecisionMismatch)
+	}
+}
+
+func TestSketchMarshalBinary(t *testing.T) {
+	for _, n := range []int{100, 100000} {
+		s := New()
+		for i := 0; i < n; i++ {
+			s.Add(uint64(i))
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &Sketch{}
+		if err := c.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if c.Count() != s.Count() || c.Sparse() != s.Sparse() {
+			t.Errorf("Count() = %v, want %v", c.Count(), s.Count())
+		}
+		if err := c.UnmarshalBinary(data[:len(data)-1]); err != ErrInvalidData {
+			t.Errorf("UnmarshalBinary() error = %v, want %v", err, ErrInvalidData)
+		}
+	}
+}
+
+// 检查误差在4个标准误差以内
+func checkCount(t *testing.T, s *Sketch, n int) {
+	t.Helper()
+	got := s.Count()
+	stdErr := 1.04 / math.Sqrt(float64(uint64(1)<<s.Precision()))
+	if math.Abs(float64(got)-float64(n)) > 4*stdErr*float64(n)+1 {
+		t.Errorf("Count() = %v, want about %v", got, n)
+	}
+}
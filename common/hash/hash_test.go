@@ -17,3 +17,20 @@ func TestInt64(t *testing.T) {
 	t.Log(a)
 	t.Log(b)
 }
+
+func TestSum128(t *testing.T) {
+	tests := []struct {
+		in     string
+		h1, h2 uint64
+	}{
+		{"", 0, 0},
+		{"hello", 0xcbd8a7b341bd9b02, 0x5b1e906a48ae1d19},
+		{"The quick brown fox jumps over the lazy dog", 0xe34bbc7bbc071b6c, 0x7a433ca9c49a9347},
+	}
+	for _, tt := range tests {
+		h1, h2 := Sum128String(tt.in)
+		if h1 != tt.h1 || h2 != tt.h2 {
+			t.Errorf("Sum128String(%q) = %x %x, want %x %x", tt.in, h1, h2, tt.h1, tt.h2)
+		}
+	}
+}
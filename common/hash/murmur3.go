@@ -0,0 +1,133 @@
+package hash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	murmur3C1 = 0x87c37b91114253d5
+	murmur3C2 = 0x4cf5ad432745937f
+)
+
+// Sum128 MurmurHash3 x64_128哈希
+// 和Hash不同，结果不依赖随机种子，可以跨进程持久化使用
+// https://github.com/aappleby/smhasher/blob/master/src/MurmurHash3.cpp
+func Sum128(b []byte) (uint64, uint64) {
+	return Sum128WithSeed(b, 0)
+}
+
+// Sum128WithSeed 带种子的MurmurHash3 x64_128哈希
+// 种子为64位，低32位与标准实现一致时结果相同
+func Sum128WithSeed(b []byte, seed uint64) (uint64, uint64) {
+	h1, h2 := seed, seed
+	length := uint64(len(b))
+
+	// 每次处理16字节
+	for len(b) >= 16 {
+		k1 := binary.LittleEndian.Uint64(b)
+		k2 := binary.LittleEndian.Uint64(b[8:])
+		b = b[16:]
+
+		h1 ^= mixK1(k1)
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+
+		h2 ^= mixK2(k2)
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+
+	// 剩余不足16字节
+	var k1, k2 uint64
+	switch len(b) {
+	case 15:
+		k2 ^= uint64(b[14]) << 48
+		fallthrough
+	case 14:
+		k2 ^= uint64(b[13]) << 40
+		fallthrough
+	case 13:
+		k2 ^= uint64(b[12]) << 32
+		fallthrough
+	case 12:
+		k2 ^= uint64(b[11]) << 24
+		fallthrough
+	case 11:
+		k2 ^= uint64(b[10]) << 16
+		fallthrough
+	case 10:
+		k2 ^= uint64(b[9]) << 8
+		fallthrough
+	case 9:
+		k2 ^= uint64(b[8])
+		h2 ^= mixK2(k2)
+		fallthrough
+	case 8:
+		k1 ^= uint64(b[7]) << 56
+		fallthrough
+	case 7:
+		k1 ^= uint64(b[6]) << 48
+		fallthrough
+	case 6:
+		k1 ^= uint64(b[5]) << 40
+		fallthrough
+	case 5:
+		k1 ^= uint64(b[4]) << 32
+		fallthrough
+	case 4:
+		k1 ^= uint64(b[3]) << 24
+		fallthrough
+	case 3:
+		k1 ^= uint64(b[2]) << 16
+		fallthrough
+	case 2:
+		k1 ^= uint64(b[1]) << 8
+		fallthrough
+	case 1:
+		k1 ^= uint64(b[0])
+		h1 ^= mixK1(k1)
+	}
+
+	h1 ^= length
+	h2 ^= length
+	h1 += h2
+	h2 += h1
+	h1 = Mix64(h1)
+	h2 = Mix64(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+// Sum128String 字符串的MurmurHash3 x64_128哈希
+func Sum128String(s string) (uint64, uint64) {
+	return Sum128([]byte(s))
+}
+
+// Mix64 MurmurHash3的fmix64
+// 把64位整数的各个位充分打散，是一个双射
+func Mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func mixK1(k1 uint64) uint64 {
+	k1 *= murmur3C1
+	k1 = bits.RotateLeft64(k1, 31)
+	k1 *= murmur3C2
+	return k1
+}
+
+func mixK2(k2 uint64) uint64 {
+	k2 *= murmur3C2
+	k2 = bits.RotateLeft64(k2, 33)
+	k2 *= murmur3C1
+	return k2
+}
@@ -0,0 +1,111 @@
+package math
+
+import (
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+// AddChecked 检查溢出的加法
+// 溢出时ok为false，sum为回绕后的结果
+func AddChecked[T constraints.Integer](a, b T) (sum T, ok bool) {
+	sum = a + b
+	return sum, (b >= 0) == (sum >= a)
+}
+
+// SubChecked 检查溢出的减法
+// 溢出时ok为false，diff为回绕后的结果
+func SubChecked[T constraints.Integer](a, b T) (diff T, ok bool) {
+	diff = a - b
+	return diff, (b >= 0) == (diff <= a)
+}
+
+// MulChecked 检查溢出的乘法
+// 溢出时ok为false，product为回绕后的结果
+func MulChecked[T constraints.Integer](a, b T) (product T, ok bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product = a * b
+	// 有符号最小值乘以-1时product/b仍然等于a，需要两个方向都检查
+	return product, product/b == a && product/a == b
+}
+
+// Pow 整数的幂
+// 溢出时ok为false；Pow(0, 0) = 1
+func Pow[T constraints.Integer](base T, exp uint) (pow T, ok bool) {
+	pow = 1
+	for {
+		if exp&1 == 1 {
+			if pow, ok = MulChecked(pow, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp == 0 {
+			return pow, true
+		}
+		if base, ok = MulChecked(base, base); !ok {
+			return 0, false
+		}
+	}
+}
+
+// AddSaturating 饱和加法
+// 溢出时返回T的最大值或最小值
+func AddSaturating[T constraints.Integer](a, b T) T {
+	sum, ok := AddChecked(a, b)
+	if ok {
+		return sum
+	}
+	if b > 0 {
+		return maxOf[T]()
+	}
+	return minOf[T]()
+}
+
+// SubSaturating 饱和减法
+// 溢出时返回T的最大值或最小值，无符号类型不会小于0
+func SubSaturating[T constraints.Integer](a, b T) T {
+	diff, ok := SubChecked(a, b)
+	if ok {
+		return diff
+	}
+	if b > 0 {
+		return minOf[T]()
+	}
+	return maxOf[T]()
+}
+
+// MulSaturating 饱和乘法
+// 溢出时返回T的最大值或最小值
+func MulSaturating[T constraints.Integer](a, b T) T {
+	product, ok := MulChecked(a, b)
+	if ok {
+		return product
+	}
+	if (a < 0) != (b < 0) {
+		return minOf[T]()
+	}
+	return maxOf[T]()
+}
+
+// T的最大值
+func maxOf[T constraints.Integer]() T {
+	var zero T
+	max := ^zero
+	if max < 0 {
+		// 有符号类型，最高位以外都是1
+		max = T(1)<<(unsafe.Sizeof(zero)*8-1) - 1
+	}
+	return max
+}
+
+// T的最小值
+func minOf[T constraints.Integer]() T {
+	var zero T
+	if ^zero < 0 {
+		return -maxOf[T]() - 1
+	}
+	return 0
+}
@@ -0,0 +1,269 @@
+package math
+
+import (
+	"errors"
+	"math"
+)
+
+// DefaultMaxBuckets 默认每个方向最多的桶数量
+const DefaultMaxBuckets = 2048
+
+// ErrIncompatibleSketch 两个DDSketch的相对误差不同，不能合并
+var ErrIncompatibleSketch = errors.New("math: incompatible sketches")
+
+// DDSketch 可合并的分位数估计
+// 按照对数划分桶，任意分位数的相对误差不超过relativeAccuracy
+// 同一相对误差的DDSketch可以跨进程合并，合并结果和直接记录所有值相同
+// 桶数量超过上限时合并值最小的桶，只影响最小的那部分分位数
+// 非线程安全，请加锁
+// https://arxiv.org/abs/1908.10693
+type DDSketch struct {
+	relativeAccuracy float64
+	gamma            float64 // 相邻桶边界的比例 (1+a)/(1-a)
+	logGamma         float64
+	maxBuckets       int
+	positive         ddStore // 正数
+	negative         ddStore // 负数，桶下标取负，使下标从小到大对应值从小到大
+	zero             uint64  // 0和非常接近0的值的数量
+	count            uint64
+	sum              float64
+	min              float64
+	max              float64
+}
+
+// NewDDSketch 创建
+// relativeAccuracy 相对误差，范围(0,1)，例如0.01表示误差不超过1%
+func NewDDSketch(relativeAccuracy float64) *DDSketch {
+	return NewDDSketchWithMaxBuckets(relativeAccuracy, DefaultMaxBuckets)
+}
+
+// NewDDSketchWithMaxBuckets 创建
+// maxBuckets 每个方向最多的桶数量，限制内存
+func NewDDSketchWithMaxBuckets(relativeAccuracy float64, maxBuckets int) *DDSketch {
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		panic("relativeAccuracy must be in range (0, 1)")
+	}
+	if maxBuckets <= 0 {
+		panic("maxBuckets must be greater than 0")
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &DDSketch{
+		relativeAccuracy: relativeAccuracy,
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		maxBuckets:       maxBuckets,
+	}
+}
+
+// Add 记录一个值
+func (s *DDSketch) Add(x float64) {
+	s.AddN(x, 1)
+}
+
+// AddN 记录n个相同的值
+func (s *DDSketch) AddN(x float64, n uint64) {
+	if n == 0 || math.IsNaN(x) {
+		return
+	}
+	switch {
+	case x > math.SmallestNonzeroFloat64:
+		s.positive.add(s.index(x), n, s.maxBuckets)
+	case x < -math.SmallestNonzeroFloat64:
+		s.negative.add(-s.index(-x), n, s.maxBuckets)
+	default:
+		s.zero += n
+	}
+	if s.count == 0 {
+		s.min, s.max = x, x
+	} else {
+		s.min = math.Min(s.min, x)
+		s.max = math.Max(s.max, x)
+	}
+	s.count += n
+	s.sum += x * float64(n)
+}
+
+// Quantile 估计分位数
+// q的范围[0,1]，例如0.99表示p99；没有值时返回0
+func (s *DDSketch) Quantile(q float64) float64 {
+	if s.count == 0 || q < 0 || q > 1 {
+		return 0
+	}
+	if q == 0 {
+		return s.min
+	}
+	if q == 1 {
+		return s.max
+	}
+	rank := uint64(q * float64(s.count-1))
+	var x float64
+	if negative := s.negative.count; rank < negative {
+		x = -s.value(-s.negative.keyAtRank(rank))
+	} else if rank < negative+s.zero {
+		x = 0
+	} else {
+		x = s.value(s.positive.keyAtRank(rank - negative - s.zero))
+	}
+	// 估计值不会超出实际范围
+	return math.Max(s.min, math.Min(s.max, x))
+}
+
+// Quantiles 估计多个分位数
+func (s *DDSketch) Quantiles(qs ...float64) []float64 {
+	values := make([]float64, len(qs))
+	for i, q := range qs {
+		values[i] = s.Quantile(q)
+	}
+	return values
+}
+
+// Merge 合并另一个DDSketch
+// 结果保存在s里
+func (s *DDSketch) Merge(other *DDSketch) error {
+	if s.gamma != other.gamma {
+		return ErrIncompatibleSketch
+	}
+	if other.count == 0 {
+		return nil
+	}
+	s.positive.merge(&other.positive, s.maxBuckets)
+	s.negative.merge(&other.negative, s.maxBuckets)
+	s.zero += other.zero
+	if s.count == 0 {
+		s.min, s.max = other.min, other.max
+	} else {
+		s.min = math.Min(s.min, other.min)
+		s.max = math.Max(s.max, other.max)
+	}
+	s.count += other.count
+	s.sum += other.sum
+	return nil
+}
+
+// Count 数量
+func (s *DDSketch) Count() uint64 {
+	return s.count
+}
+
+// Sum 总和
+func (s *DDSketch) Sum() float64 {
+	return s.sum
+}
+
+// Mean 均值
+func (s *DDSketch) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// Min 最小值
+func (s *DDSketch) Min() float64 {
+	return s.min
+}
+
+// Max 最大值
+func (s *DDSketch) Max() float64 {
+	return s.max
+}
+
+// RelativeAccuracy 相对误差
+func (s *DDSketch) RelativeAccuracy() float64 {
+	return s.relativeAccuracy
+}
+
+// Reset 重置
+func (s *DDSketch) Reset() {
+	s.positive = ddStore{}
+	s.negative = ddStore{}
+	s.zero, s.count, s.sum, s.min, s.max = 0, 0, 0, 0, 0
+}
+
+// 值对应的桶下标，桶i的范围是(gamma^(i-1), gamma^i]
+func (s *DDSketch) index(x float64) int {
+	return int(math.Ceil(math.Log(x) / s.logGamma))
+}
+
+// 桶的代表值，桶内任意值与它的相对误差不超过relativeAccuracy
+func (s *DDSketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+// 连续的桶
+type ddStore struct {
+	buckets []uint64 // 桶计数
+	offset  int      // buckets[0]对应的桶下标
+	count   uint64   // 总数
+}
+
+// 增加桶计数
+// 桶数量超过上限时，把下标最小的桶合并到一起
+func (s *ddStore) add(index int, n uint64, maxBuckets int) {
+	s.count += n
+	if len(s.buckets) == 0 {
+		s.buckets = make([]uint64, 1)
+		s.offset = index
+	}
+	if index < s.offset {
+		// 已经合并过的范围内
+		if len(s.buckets) >= maxBuckets {
+			s.buckets[0] += n
+			return
+		}
+		grow := s.offset - index
+		if len(s.buckets)+grow > maxBuckets {
+			grow = maxBuckets - len(s.buckets)
+		}
+		buckets := make([]uint64, len(s.buckets)+grow)
+		copy(buckets[grow:], s.buckets)
+		s.buckets = buckets
+		s.offset -= grow
+		if index < s.offset {
+			index = s.offset
+		}
+	} else if last := s.offset + len(s.buckets) - 1; index > last {
+		s.buckets = append(s.buckets, make([]uint64, index-last)...)
+		s.collapse(maxBuckets)
+		if index < s.offset {
+			index = s.offset
+		}
+	}
+	s.buckets[index-s.offset] += n
+}
+
+// 合并下标最小的桶使桶数量不超过上限
+func (s *ddStore) collapse(maxBuckets int) {
+	extra := len(s.buckets) - maxBuckets
+	if extra <= 0 {
+		return
+	}
+	var sum uint64
+	for _, c := range s.buckets[:extra+1] {
+		sum += c
+	}
+	s.buckets = append(s.buckets[:0:0], s.buckets[extra:]...)
+	s.buckets[0] = sum
+	s.offset += extra
+}
+
+// 合并另一个store
+func (s *ddStore) merge(other *ddStore, maxBuckets int) {
+	for i, c := range other.buckets {
+		if c > 0 {
+			s.add(other.offset+i, c, maxBuckets)
+		}
+	}
+}
+
+// 排名为rank的值所在的桶下标，rank从0开始
+func (s *ddStore) keyAtRank(rank uint64) int {
+	var n uint64
+	for i, c := range s.buckets {
+		n += c
+		if n > rank {
+			return s.offset + i
+		}
+	}
+	return s.offset + len(s.buckets) - 1
+}
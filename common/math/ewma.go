@@ -0,0 +1,111 @@
+package math
+
+import (
+	"math"
+	"time"
+)
+
+// EWMA 按时间衰减的指数加权移动平均
+// 和按次数衰减不同，权重只取决于两次更新的时间间隔，更新频率变化时结果仍然稳定
+// 经过halfLife时间后，旧值的权重衰减一半
+// 非线程安全，请加锁
+type EWMA struct {
+	tau   float64   // 时间常数，单位纳秒
+	value float64   // 当前值
+	last  time.Time // 上一次更新时间
+	init  bool      // 是否已经有值
+}
+
+// NewEWMA 创建
+// halfLife 半衰期
+func NewEWMA(halfLife time.Duration) *EWMA {
+	if halfLife <= 0 {
+		panic("halfLife must be greater than 0")
+	}
+	return &EWMA{tau: float64(halfLife) / math.Ln2}
+}
+
+// Update 使用当前时间更新
+func (e *EWMA) Update(x float64) {
+	e.UpdateAt(x, time.Now())
+}
+
+// UpdateAt 使用指定时间更新
+// 第一次更新直接作为当前值
+func (e *EWMA) UpdateAt(x float64, now time.Time) {
+	if !e.init {
+		e.value, e.last, e.init = x, now, true
+		return
+	}
+	dt := float64(now.Sub(e.last))
+	if dt < 0 {
+		dt = 0
+	}
+	// 新值的权重
+	alpha := 1 - math.Exp(-dt/e.tau)
+	e.value += alpha * (x - e.value)
+	e.last = now
+}
+
+// Value 当前值
+// 没有值时返回0
+func (e *EWMA) Value() float64 {
+	return e.value
+}
+
+// Reset 重置
+func (e *EWMA) Reset() {
+	e.value, e.last, e.init = 0, time.Time{}, false
+}
+
+// EWMARate 按时间衰减的指数加权事件速率
+// 每次事件贡献的权重随时间指数衰减，速率单位为每秒事件数
+// 稳定速率r下，结果收敛到r
+// 非线程安全，请加锁
+type EWMARate struct {
+	tau  float64   // 时间常数，单位秒
+	rate float64   // 上一次更新时的速率
+	last time.Time // 上一次更新时间
+}
+
+// NewEWMARate 创建
+// halfLife 半衰期
+func NewEWMARate(halfLife time.Duration) *EWMARate {
+	if halfLife <= 0 {
+		panic("halfLife must be greater than 0")
+	}
+	return &EWMARate{tau: halfLife.Seconds() / math.Ln2}
+}
+
+// Mark 使用当前时间记录n次事件
+func (r *EWMARate) Mark(n float64) {
+	r.MarkAt(n, time.Now())
+}
+
+// MarkAt 使用指定时间记录n次事件
+func (r *EWMARate) MarkAt(n float64, now time.Time) {
+	r.rate = r.RateAt(now) + n/r.tau
+	r.last = now
+}
+
+// Rate 当前时间的速率
+func (r *EWMARate) Rate() float64 {
+	return r.RateAt(time.Now())
+}
+
+// RateAt 指定时间的速率
+func (r *EWMARate) RateAt(now time.Time) float64 {
+	if r.last.IsZero() {
+		return 0
+	}
+	dt := now.Sub(r.last).Seconds()
+	if dt < 0 {
+		dt = 0
+	}
+	return r.rate * math.Exp(-dt/r.tau)
+}
+
+// Reset 重置
+func (r *EWMARate) Reset() {
+	r.rate, r.last = 0, time.Time{}
+}
@@ -0,0 +1,371 @@
+package math
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// ErrValueOutOfRange 值超出直方图的记录范围
+var ErrValueOutOfRange = errors.New("math: value out of range")
+
+// HDRHistogram 高动态范围直方图
+// 桶按照对数-线性划分：每个2的幂区间内再等分为若干子桶，保证记录的值有significantFigures位有效数字
+// 内存只取决于范围和精度，与记录的数量无关
+// 记录是无锁的，可以被多个goroutine并发调用
+// http://hdrhistogram.org/
+type HDRHistogram struct {
+	lowestDiscernibleValue      int64
+	highestTrackableValue       int64
+	significantFigures          int
+	unitMagnitude               uint
+	subBucketHalfCountMagnitude uint
+	subBucketCount              int64
+	subBucketHalfCount          int64
+	subBucketMask               int64
+	bucketCount                 int
+	counts                      []int64 // 每个子桶的计数，原子操作
+	totalCount                  int64   // 总数，原子操作
+	min                         int64   // 最小值，原子操作
+	max                         int64   // 最大值，原子操作
+}
+
+// NewHDRHistogram 创建
+// lowestDiscernibleValue 可区分的最小值，至少为1，例如以微秒记录但只关心毫秒时可以设为1000
+// highestTrackableValue 可记录的最大值，至少为lowestDiscernibleValue的2倍
+// significantFigures 有效数字位数，范围[1,5]，例如3表示误差不超过0.1%
+func NewHDRHistogram(lowestDiscernibleValue, highestTrackableValue int64, significantFigures int) *HDRHistogram {
+	if lowestDiscernibleValue < 1 {
+		panic("lowestDiscernibleValue must be greater than 0")
+	}
+	if highestTrackableValue < 2*lowestDiscernibleValue {
+		panic("highestTrackableValue must be at least 2 * lowestDiscernibleValue")
+	}
+	if significantFigures < 1 || significantFigures > 5 {
+		panic("significantFigures must be in range [1, 5]")
+	}
+	// 单位精度能表示的最大值
+	largestValueWithSingleUnitResolution := 2 * int64(math.Pow10(significantFigures))
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(float64(largestValueWithSingleUnitResolution))))
+	subBucketHalfCountMagnitude := subBucketCountMagnitude - 1
+	unitMagnitude := uint(bits.Len64(uint64(lowestDiscernibleValue)) - 1)
+	subBucketCount := int64(1) << subBucketCountMagnitude
+
+	// 需要多少个2的幂区间才能覆盖最大值
+	smallestUntrackableValue := subBucketCount << unitMagnitude
+	bucketCount := 1
+	for smallestUntrackableValue <= highestTrackableValue {
+		if smallestUntrackableValue > math.MaxInt64/2 {
+			bucketCount++
+			break
+		}
+		smallestUntrackableValue <<= 1
+		bucketCount++
+	}
+
+	h := &HDRHistogram{
+		lowestDiscernibleValue:      lowestDiscernibleValue,
+		highestTrackableValue:       highestTrackableValue,
+		significantFigures:          significantFigures,
+		unitMagnitude:               unitMagnitude,
+		subBucketHalfCountMagnitude: subBucketHalfCountMagnitude,
+		subBucketCount:              subBucketCount,
+		subBucketHalfCount:          subBucketCount / 2,
+		subBucketMask:               (subBucketCount - 1) << unitMagnitude,
+		bucketCount:                 bucketCount,
+		min:                         math.MaxInt64,
+	}
+	h.counts = make([]int64, (int64(bucketCount)+1)*h.subBucketHalfCount)
+	return h
+}
+
+// Record 记录一个值
+func (h *HDRHistogram) Record(v int64) error {
+	return h.RecordN(v, 1)
+}
+
+// RecordN 记录n个相同的值
+func (h *HDRHistogram) RecordN(v, n int64) error {
+	if v < 0 || v > h.highestTrackableValue {
+		return ErrValueOutOfRange
+	}
+	index := h.countsIndex(v)
+	if index < 0 || index >= len(h.counts) {
+		return ErrValueOutOfRange
+	}
+	atomic.AddInt64(&h.counts[index], n)
+	atomic.AddInt64(&h.totalCount, n)
+	for {
+		min := atomic.LoadInt64(&h.min)
+		if v >= min || atomic.CompareAndSwapInt64(&h.min, min, v) {
+			break
+		}
+	}
+	for {
+		max := atomic.LoadInt64(&h.max)
+		if v <= max || atomic.CompareAndSwapInt64(&h.max, max, v) {
+			break
+		}
+	}
+	return nil
+}
+
+// ValueAtQuantile 估计分位数
+// q的范围[0,1]，例如0.99表示p99；返回值与实际值在有效数字范围内相等
+func (h *HDRHistogram) ValueAtQuantile(q float64) int64 {
+	q = math.Max(0, math.Min(1, q))
+	counts := h.loadCounts()
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	target := int64(q*float64(total) + 0.5)
+	if target < 1 {
+		target = 1
+	}
+	var cum int64
+	for i, c := range counts {
+		cum += c
+		if cum >= target {
+			return h.highestEquivalentValue(h.valueFromIndex(i))
+		}
+	}
+	return 0
+}
+
+// Min 最小值
+// 返回的是最小值所在子桶的最小值
+func (h *HDRHistogram) Min() int64 {
+	min := atomic.LoadInt64(&h.min)
+	if min == math.MaxInt64 {
+		return 0
+	}
+	return h.lowestEquivalentValue(min)
+}
+
+// Max 最大值
+// 返回的是最大值所在子桶的最大值
+func (h *HDRHistogram) Max() int64 {
+	max := atomic.LoadInt64(&h.max)
+	if max == 0 {
+		return 0
+	}
+	return h.highestEquivalentValue(max)
+}
+
+// Mean 均值
+// 每个子桶使用中间值计算
+func (h *HDRHistogram) Mean() float64 {
+	var total, sum float64
+	for i, c := range h.loadCounts() {
+		if c == 0 {
+			continue
+		}
+		total += float64(c)
+		sum += float64(c) * float64(h.medianEquivalentValue(h.valueFromIndex(i)))
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// StdDev 标准差
+func (h *HDRHistogram) StdDev() float64 {
+	mean := h.Mean()
+	var total, sum float64
+	for i, c := range h.loadCounts() {
+		if c == 0 {
+			continue
+		}
+		d := float64(h.medianEquivalentValue(h.valueFromIndex(i))) - mean
+		total += float64(c)
+		sum += float64(c) * d * d
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / total)
+}
+
+// TotalCount 记录的总数
+func (h *HDRHistogram) TotalCount() int64 {
+	return atomic.LoadInt64(&h.totalCount)
+}
+
+// Merge 合并另一个直方图
+// 两边配置不同时按照值重新记录，超出范围的值被丢弃，返回被丢弃的数量
+func (h *HDRHistogram) Merge(other *HDRHistogram) int64 {
+	var dropped int64
+	for i, c := range other.loadCounts() {
+		if c == 0 {
+			continue
+		}
+		if err := h.RecordN(other.valueFromIndex(i), c); err != nil {
+			dropped += c
+		}
+	}
+	return dropped
+}
+
+// Snapshot 复制当前的直方图
+func (h *HDRHistogram) Snapshot() *HDRHistogram {
+	s := h.emptyCopy()
+	for i := range h.counts {
+		s.counts[i] = atomic.LoadInt64(&h.counts[i])
+	}
+	s.totalCount = atomic.LoadInt64(&h.totalCount)
+	s.min = atomic.LoadInt64(&h.min)
+	s.max = atomic.LoadInt64(&h.max)
+	return s
+}
+
+// IntervalSnapshot 取出上一次调用以来记录的值，并重置直方图
+// 每个子桶原子地取出并清零，并发记录的值要么在本次快照中，要么在下一次快照中，不会丢失
+func (h *HDRHistogram) IntervalSnapshot() *HDRHistogram {
+	s := h.emptyCopy()
+	s.min = atomic.SwapInt64(&h.min, math.MaxInt64)
+	s.max = atomic.SwapInt64(&h.max, 0)
+	for i := range h.counts {
+		c := atomic.SwapInt64(&h.counts[i], 0)
+		s.counts[i] = c
+		s.totalCount += c
+	}
+	atomic.AddInt64(&h.totalCount, -s.totalCount)
+	return s
+}
+
+// Reset 重置
+func (h *HDRHistogram) Reset() {
+	h.IntervalSnapshot()
+}
+
+// SignificantFigures 有效数字位数
+func (h *HDRHistogram) SignificantFigures() int {
+	return h.significantFigures
+}
+
+// WriteText 以文本形式输出分位数分布
+// 格式与HdrHistogram的outputPercentileDistribution相同，值除以scale后输出，例如以微秒记录时scale为1000输出毫秒
+func (h *HDRHistogram) WriteText(w io.Writer, scale float64) error {
+	const ticksPerHalfDistance = 5
+	counts := h.loadCounts()
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+
+	if _, err := fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)"); err != nil {
+		return err
+	}
+	var cum int64
+	percentile := 0.0
+	for i := 0; i < len(counts) && cum < total; i++ {
+		if counts[i] == 0 {
+			continue
+		}
+		cum += counts[i]
+		value := float64(h.highestEquivalentValue(h.valueFromIndex(i))) / scale
+		// 一个子桶可能跨越多个输出的分位点
+		for float64(cum)*100/float64(total) >= percentile {
+			if cum == total {
+				if _, err := fmt.Fprintf(w, "%12.3f %14.12f %10d\n", value, 1.0, cum); err != nil {
+					return err
+				}
+				break
+			}
+			if _, err := fmt.Fprintf(w, "%12.3f %14.12f %10d %14.2f\n", value, percentile/100, cum, 1/(1-percentile/100)); err != nil {
+				return err
+			}
+			// 越接近100%，输出的分位点越密
+			halfDistance := math.Floor(math.Log2(100/(100-percentile))) + 1
+			percentile += 100 / (ticksPerHalfDistance * math.Pow(2, halfDistance))
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n"+
+		"#[Max     = %12.3f, Total count    = %12d]\n"+
+		"#[Buckets = %12d, SubBuckets     = %12d]\n",
+		h.Mean()/scale, h.StdDev()/scale, float64(h.Max())/scale, total, h.bucketCount, h.subBucketCount)
+	return err
+}
+
+// 原子地读取所有计数
+func (h *HDRHistogram) loadCounts() []int64 {
+	counts := make([]int64, len(h.counts))
+	for i := range h.counts {
+		counts[i] = atomic.LoadInt64(&h.counts[i])
+	}
+	return counts
+}
+
+// 相同配置的空直方图
+func (h *HDRHistogram) emptyCopy() *HDRHistogram {
+	s := *h
+	s.counts = make([]int64, len(h.counts))
+	s.totalCount, s.min, s.max = 0, math.MaxInt64, 0
+	return &s
+}
+
+// 值所在的2的幂区间
+func (h *HDRHistogram) bucketIndex(v int64) int {
+	// 小于子桶数量的值都在第0个区间
+	leadingZeroCountBase := 64 - h.unitMagnitude - h.subBucketHalfCountMagnitude - 1
+	return int(leadingZeroCountBase) - bits.LeadingZeros64(uint64(v|h.subBucketMask))
+}
+
+// 值在区间内的子桶下标
+func (h *HDRHistogram) subBucketIndex(v int64, bucketIndex int) int64 {
+	return v >> (uint(bucketIndex) + h.unitMagnitude)
+}
+
+// 值对应的计数下标
+// 除第0个区间外，每个区间只使用后一半子桶，前一半与上一个区间重叠
+func (h *HDRHistogram) countsIndex(v int64) int {
+	bucketIndex := h.bucketIndex(v)
+	subBucketIndex := h.subBucketIndex(v, bucketIndex)
+	bucketBaseIndex := int64(bucketIndex+1) << h.subBucketHalfCountMagnitude
+	return int(bucketBaseIndex + subBucketIndex - h.subBucketHalfCount)
+}
+
+// 计数下标对应子桶的最小值
+func (h *HDRHistogram) valueFromIndex(index int) int64 {
+	bucketIndex := (index >> h.subBucketHalfCountMagnitude) - 1
+	subBucketIndex := int64(index)&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucketIndex < 0 {
+		subBucketIndex -= h.subBucketHalfCount
+		bucketIndex = 0
+	}
+	return subBucketIndex << (uint(bucketIndex) + h.unitMagnitude)
+}
+
+// 值所在子桶的宽度
+func (h *HDRHistogram) sizeOfEquivalentValueRange(v int64) int64 {
+	bucketIndex := h.bucketIndex(v)
+	if h.subBucketIndex(v, bucketIndex) >= h.subBucketCount {
+		bucketIndex++
+	}
+	return int64(1) << (h.unitMagnitude + uint(bucketIndex))
+}
+
+// 值所在子桶的最小值
+func (h *HDRHistogram) lowestEquivalentValue(v int64) int64 {
+	bucketIndex := h.bucketIndex(v)
+	return h.subBucketIndex(v, bucketIndex) << (uint(bucketIndex) + h.unitMagnitude)
+}
+
+// 值所在子桶的最大值
+func (h *HDRHistogram) highestEquivalentValue(v int64) int64 {
+	return h.lowestEquivalentValue(v) + h.sizeOfEquivalentValueRange(v) - 1
+}
+
+// 值所在子桶的中间值
+func (h *HDRHistogram) medianEquivalentValue(v int64) int64 {
+	return h.lowestEquivalentValue(v) + h.sizeOfEquivalentValueRange(v)>>1
+}
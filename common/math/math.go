@@ -8,9 +8,15 @@ import (
 )
 
 // Split 把数n分成m份
-// 返回每一份大小和最后一份的大小
+// 返回每一份大小和最后一份的大小，n为0时都为0；m为0会panic
 func Split(n, m uint) (uint, uint) {
-	per := (n + m - 1) / m
+	if m == 0 {
+		panic("m must be greater than 0")
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	per := CeilDiv(n, m)
 	last := n % per
 	if last == 0 {
 		last = per
@@ -56,15 +62,87 @@ func IsPowOf2[T constraints.Unsigned](n T) bool {
 }
 
 // RoundUpPowOf2 round up to nearest power of two
+// RoundUpPowOf2(0) = 1; panics if the result overflows T.
 func RoundUpPowOf2[T constraints.Unsigned](n T) T {
-	return 1 << FindLastBitSet(n-1)
+	if n <= 1 {
+		return 1
+	}
+	p := T(1) << FindLastBitSet(n-1)
+	if p == 0 {
+		panic("math: RoundUpPowOf2 overflow")
+	}
+	return p
 }
 
 // RoundDownPowOf2 round down to nearest power of two
+// RoundDownPowOf2(0) = 0.
 func RoundDownPowOf2[T constraints.Unsigned](n T) T {
+	if n == 0 {
+		return 0
+	}
 	return 1 << (FindLastBitSet(n) - 1)
 }
 
+// Clamp 把v限制在[lo,hi]范围内
+// lo大于hi会panic
+func Clamp[T constraints.Ordered](v, lo, hi T) T {
+	if lo > hi {
+		panic("lo must be less than or equal to hi")
+	}
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// CeilDiv 向上取整的除法
+// 不会像(a+b-1)/b那样溢出；b为0会panic
+func CeilDiv[T constraints.Integer](a, b T) T {
+	q := a / b
+	// 商为正且有余数时进位，商为负时截断已经是向上取整
+	if a%b != 0 && (a < 0) == (b < 0) {
+		q++
+	}
+	return q
+}
+
+// GCD 最大公约数
+// 结果不小于0，GCD(0, 0) = 0；结果为有符号类型最小值的绝对值时无法表示，会panic
+func GCD[T constraints.Integer](a, b T) T {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		a = -a
+		if a < 0 {
+			panic("math: GCD overflow")
+		}
+	}
+	return a
+}
+
+// LCM 最小公倍数
+// 结果不小于0，有一个为0时结果为0；溢出时ok为false
+func LCM[T constraints.Integer](a, b T) (lcm T, ok bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	lcm, ok = MulChecked(a/GCD(a, b), b)
+	if !ok {
+		return 0, false
+	}
+	if lcm < 0 {
+		lcm = -lcm
+		if lcm < 0 {
+			return 0, false
+		}
+	}
+	return lcm, true
+}
+
 // FindLastBitSet find last (most-significant) bit set
 // This is defined the same way as ffs.
 // Note FindLastBitSet(0) = 0, FindLastBitSet(1) = 1, FindLastBitSet(0x80000000) = 32.
@@ -0,0 +1,148 @@
+package math
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		n, m      uint
+		per, last uint
+	}{
+		{0, 3, 0, 0},
+		{10, 4, 3, 1},
+		{9, 3, 3, 3},
+		{1, 8, 1, 1},
+		{math.MaxUint, 2, math.MaxUint/2 + 1, math.MaxUint / 2},
+	}
+	for _, tt := range tests {
+		if per, last := Split(tt.n, tt.m); per != tt.per || last != tt.last {
+			t.Errorf("Split(%v, %v) = %v %v, want %v %v", tt.n, tt.m, per, last, tt.per, tt.last)
+		}
+	}
+}
+
+func TestPowOf2(t *testing.T) {
+	tests := []struct {
+		n, up, down uint64
+	}{
+		{0, 1, 0},
+		{1, 1, 1},
+		{3, 4, 2},
+		{1 << 40, 1 << 40, 1 << 40},
+		{1<<63 - 1, 1 << 63, 1 << 62},
+	}
+	for _, tt := range tests {
+		if got := RoundUpPowOf2(tt.n); got != tt.up {
+			t.Errorf("RoundUpPowOf2(%v) = %v, want %v", tt.n, got, tt.up)
+		}
+		if got := RoundDownPowOf2(tt.n); got != tt.down {
+			t.Errorf("RoundDownPowOf2(%v) = %v, want %v", tt.n, got, tt.down)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RoundUpPowOf2(%v) did not panic", uint8(129))
+		}
+	}()
+	RoundUpPowOf2(uint8(129))
+}
+
+func TestHelpers(t *testing.T) {
+	if got := Clamp(15, 0, 10); got != 10 {
+		t.Errorf("Clamp() = %v, want %v", got, 10)
+	}
+	if got := GCD(-12, 18); got != 6 {
+		t.Errorf("GCD() = %v, want %v", got, 6)
+	}
+	if got, ok := LCM(int8(-4), 6); got != 12 || !ok {
+		t.Errorf("LCM() = %v %v, want %v", got, ok, 12)
+	}
+	if _, ok := LCM(int8(64), 3); ok {
+		t.Errorf("LCM() ok = %v, want %v", ok, false)
+	}
+	if got, ok := Pow(int64(-2), 63); got != math.MinInt64 || !ok {
+		t.Errorf("Pow() = %v %v, want %v", got, ok, int64(math.MinInt64))
+	}
+	if got := AddSaturating(int8(100), 100); got != math.MaxInt8 {
+		t.Errorf("AddSaturating() = %v, want %v", got, math.MaxInt8)
+	}
+	if got := SubSaturating(uint(1), 2); got != 0 {
+		t.Errorf("SubSaturating() = %v, want %v", got, 0)
+	}
+	if got := MulSaturating(int32(-1<<20), 1<<20); got != math.MinInt32 {
+		t.Errorf("MulSaturating() = %v, want %v", got, math.MinInt32)
+	}
+}
+
+// 用big.Int计算的结果是否在[min,max]内
+func inRange(x *big.Int, min, max int64) bool {
+	return x.Cmp(big.NewInt(min)) >= 0 && x.Cmp(big.NewInt(max)) <= 0
+}
+
+func FuzzChecked(f *testing.F) {
+	f.Add(int64(0), int64(0))
+	f.Add(int64(math.MaxInt64), int64(1))
+	f.Add(int64(math.MinInt64), int64(-1))
+	f.Add(int64(-128), int64(-1))
+	f.Add(int64(3037000500), int64(3037000500))
+	f.Fuzz(func(t *testing.T, a, b int64) {
+		x, y := big.NewInt(a), big.NewInt(b)
+		want := map[string]*big.Int{
+			"Add": new(big.Int).Add(x, y),
+			"Sub": new(big.Int).Sub(x, y),
+			"Mul": new(big.Int).Mul(x, y),
+		}
+		check := func(name string, got int64, ok bool, min, max int64) {
+			w := want[name]
+			if ok != inRange(w, min, max) || ok && got != w.Int64() {
+				t.Errorf("%sChecked(%v, %v) = %v %v, want %v", name, a, b, got, ok, w)
+			}
+		}
+		s, ok := AddChecked(a, b)
+		check("Add", s, ok, math.MinInt64, math.MaxInt64)
+		s, ok = SubChecked(a, b)
+		check("Sub", s, ok, math.MinInt64, math.MaxInt64)
+		s, ok = MulChecked(a, b)
+		check("Mul", s, ok, math.MinInt64, math.MaxInt64)
+
+		// 小类型更容易溢出
+		s8, ok := AddChecked(int8(a), int8(b))
+		want["Add"] = big.NewInt(int64(int8(a)) + int64(int8(b)))
+		check("Add", int64(s8), ok, math.MinInt8, math.MaxInt8)
+		u8, ok := MulChecked(uint8(a), uint8(b))
+		want["Mul"] = big.NewInt(int64(uint8(a)) * int64(uint8(b)))
+		check("Mul", int64(u8), ok, 0, math.MaxUint8)
+		u8, ok = SubChecked(uint8(a), uint8(b))
+		want["Sub"] = big.NewInt(int64(uint8(a)) - int64(uint8(b)))
+		check("Sub", int64(u8), ok, 0, math.MaxUint8)
+
+		if a != math.MinInt64 && b != 0 {
+			q := CeilDiv(a, b)
+			r := new(big.Rat).SetFrac(x, y)
+			want := new(big.Int).Quo(r.Num(), r.Denom())
+			if r.Num().Sign() > 0 && !r.IsInt() {
+				want.Add(want, big.NewInt(1))
+			}
+			if q != want.Int64() {
+				t.Errorf("CeilDiv(%v, %v) = %v, want %v", a, b, q, want)
+			}
+		}
+	})
+}
+
+func FuzzPow(f *testing.F) {
+	f.Add(int64(2), uint(62))
+	f.Add(int64(-3), uint(39))
+	f.Add(int64(-1), uint(1<<20))
+	f.Fuzz(func(t *testing.T, base int64, exp uint) {
+		exp %= 128
+		want := new(big.Int).Exp(big.NewInt(base), big.NewInt(int64(exp)), nil)
+		got, ok := Pow(base, exp)
+		if ok != inRange(want, math.MinInt64, math.MaxInt64) || ok && got != want.Int64() {
+			t.Errorf("Pow(%v, %v) = %v %v, want %v", base, exp, got, ok, want)
+		}
+	})
+}
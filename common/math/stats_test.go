@@ -0,0 +1,163 @@
+package math
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWelford(t *testing.T) {
+	a, b := NewWelford(), NewWelford()
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	for i, x := range values {
+		if i < 3 {
+			a.Add(x)
+		} else {
+			b.Add(x)
+		}
+	}
+	a.Merge(b)
+	if a.Count() != 8 || a.Mean() != 5 || a.Variance() != 4 || a.StdDev() != 2 {
+		t.Errorf("Welford = %v %v %v, want 8 5 4", a.Count(), a.Mean(), a.Variance())
+	}
+	if a.Min() != 2 || a.Max() != 9 {
+		t.Errorf("Min() Max() = %v %v, want 2 9", a.Min(), a.Max())
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	e := NewEWMA(time.Second)
+	now := time.Now()
+	e.UpdateAt(10, now)
+	e.UpdateAt(20, now.Add(time.Second))
+	if math.Abs(e.Value()-15) > 1e-9 {
+		t.Errorf("Value() = %v, want %v", e.Value(), 15)
+	}
+
+	r := NewEWMARate(time.Second)
+	for i := 0; i < 10000; i++ {
+		r.MarkAt(1, now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if rate := r.RateAt(now.Add(10 * time.Second)); math.Abs(rate-1000) > 10 {
+		t.Errorf("RateAt() = %v, want about %v", rate, 1000)
+	}
+}
+
+func TestDDSketch(t *testing.T) {
+	const accuracy = 0.01
+	source := rand.New(rand.NewSource(1))
+	a, b := NewDDSketch(accuracy), NewDDSketch(accuracy)
+	values := make([]float64, 100000)
+	for i := range values {
+		// 对数正态分布，类似请求延迟
+		values[i] = math.Exp(source.NormFloat64()) * 100
+		if i%2 == 0 {
+			a.Add(values[i])
+		} else {
+			b.Add(values[i])
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	sort.Float64s(values)
+	for _, q := range []float64{0.5, 0.9, 0.95, 0.99, 0.999} {
+		want := values[int(q*float64(len(values)-1))]
+		got := a.Quantile(q)
+		if math.Abs(got-want)/want > accuracy {
+			t.Errorf("Quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if a.Count() != uint64(len(values)) {
+		t.Errorf("Count() = %v, want %v", a.Count(), len(values))
+	}
+	if err := a.Merge(NewDDSketch(0.02)); err != ErrIncompatibleSketch {
+		t.Errorf("Merge() error = %v, want %v", err, ErrIncompatibleSketch)
+	}
+}
+
+func TestDDSketchMaxBuckets(t *testing.T) {
+	s := NewDDSketchWithMaxBuckets(0.01, 64)
+	for i := 1; i <= 10000; i++ {
+		s.Add(float64(i))
+		s.Add(-float64(i))
+	}
+	// 一共20000个值，p99排名为19799，是正数中的第9800个
+	if p99 := s.Quantile(0.99); math.Abs(p99-9800)/9800 > 0.01 {
+		t.Errorf("Quantile(0.99) = %v, want %v", p99, 9800)
+	}
+	if len(s.positive.buckets) > 64 || len(s.negative.buckets) > 64 {
+		t.Errorf("buckets = %v %v, want <= 64", len(s.positive.buckets), len(s.negative.buckets))
+	}
+}
+
+func TestDDSketchMaxBucketsNegative(t *testing.T) {
+	// 300个桶能覆盖的范围约为1.0202^300≈400倍
+	s := NewDDSketchWithMaxBuckets(0.01, 300)
+	for i := 1; i <= 10000; i++ {
+		s.Add(-float64(i))
+	}
+	// 负数合并的是值最小的桶，接近0的高分位数不受影响
+	// 排名9989对应-11，排名9899对应-101
+	for _, tt := range []struct{ q, want float64 }{{0.999, -11}, {0.99, -101}} {
+		if got := s.Quantile(tt.q); math.Abs(got-tt.want)/-tt.want > 0.01 {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	// 被合并的最小值偏大
+	if p01 := s.Quantile(0.01); p01 < -9900 {
+		t.Errorf("Quantile(0.01) = %v, want >= %v", p01, -9900)
+	}
+}
+
+func TestHDRHistogram(t *testing.T) {
+	h := NewHDRHistogram(1, 3600*1000*1000, 3)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for v := int64(g + 1); v <= 100000; v += 4 {
+				if err := h.Record(v); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if h.TotalCount() != 100000 {
+		t.Fatalf("TotalCount() = %v, want %v", h.TotalCount(), 100000)
+	}
+	for _, q := range []float64{0.5, 0.9, 0.99, 0.999, 1} {
+		want := q * 100000
+		if got := float64(h.ValueAtQuantile(q)); math.Abs(got-want)/want > 0.001 {
+			t.Errorf("ValueAtQuantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if h.Min() != 1 || math.Abs(float64(h.Max())-100000)/100000 > 0.001 {
+		t.Errorf("Min() Max() = %v %v, want 1 100000", h.Min(), h.Max())
+	}
+	if err := h.Record(-1); err != ErrValueOutOfRange {
+		t.Errorf("Record(-1) = %v, want %v", err, ErrValueOutOfRange)
+	}
+
+	other := NewHDRHistogram(1000, 3600*1000*1000, 2)
+	other.RecordN(1000000, 100000)
+	if dropped := h.Merge(other); dropped != 0 || h.TotalCount() != 200000 {
+		t.Errorf("Merge() = %v %v, want 0 200000", dropped, h.TotalCount())
+	}
+
+	s := h.IntervalSnapshot()
+	if s.TotalCount() != 200000 || h.TotalCount() != 0 || h.ValueAtQuantile(0.5) != 0 {
+		t.Errorf("IntervalSnapshot() = %v %v, want 200000 0", s.TotalCount(), h.TotalCount())
+	}
+	var b strings.Builder
+	if err := s.WriteText(&b, 1000); err != nil || !strings.Contains(b.String(), "Total count    =       200000") {
+		t.Errorf("WriteText() = %v\n%v", err, b.String())
+	}
+}
@@ -0,0 +1,104 @@
+package math
+
+import "math"
+
+// Welford 流式计算均值和方差
+// 每次添加只需要O(1)时间和空间，且数值稳定，不会像直接累加平方和那样损失精度
+// 非线程安全，请加锁
+// https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Welford's_online_algorithm
+type Welford struct {
+	count uint64  // 数量
+	mean  float64 // 均值
+	m2    float64 // 与均值差的平方和
+	min   float64 // 最小值
+	max   float64 // 最大值
+}
+
+// NewWelford 创建
+func NewWelford() *Welford {
+	return &Welford{}
+}
+
+// Add 添加一个值
+func (w *Welford) Add(x float64) {
+	w.count++
+	if w.count == 1 {
+		w.min, w.max = x, x
+	} else {
+		w.min = math.Min(w.min, x)
+		w.max = math.Max(w.max, x)
+	}
+	delta := x - w.mean
+	w.mean += delta / float64(w.count)
+	w.m2 += delta * (x - w.mean)
+}
+
+// Merge 合并另一个Welford
+// Chan的并行算法，结果和把两边的值依次添加相同
+func (w *Welford) Merge(other *Welford) {
+	if other.count == 0 {
+		return
+	}
+	if w.count == 0 {
+		*w = *other
+		return
+	}
+	count := w.count + other.count
+	delta := other.mean - w.mean
+	w.mean += delta * float64(other.count) / float64(count)
+	w.m2 += other.m2 + delta*delta*float64(w.count)*float64(other.count)/float64(count)
+	w.count = count
+	w.min = math.Min(w.min, other.min)
+	w.max = math.Max(w.max, other.max)
+}
+
+// Count 数量
+func (w *Welford) Count() uint64 {
+	return w.count
+}
+
+// Mean 均值
+// 没有值时返回0
+func (w *Welford) Mean() float64 {
+	return w.mean
+}
+
+// Variance 总体方差
+// 没有值时返回0
+func (w *Welford) Variance() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return w.m2 / float64(w.count)
+}
+
+// SampleVariance 样本方差
+// 少于2个值时返回0
+func (w *Welford) SampleVariance() float64 {
+	if w.count < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.count-1)
+}
+
+// StdDev 总体标准差
+func (w *Welford) StdDev() float64 {
+	return math.Sqrt(w.Variance())
+}
+
+// Min 最小值
+// 没有值时返回0
+func (w *Welford) Min() float64 {
+	return w.min
+}
+
+// Max 最大值
+// 没有值时返回0
+func (w *Welford) Max() float64 {
+	return w.max
+}
+
+// Reset 重置
+func (w *Welford) Reset() {
+	*w = Welford{}
+}
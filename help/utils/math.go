@@ -1,9 +1,31 @@
 package utils
 
-import "math"
+import (
+	"math"
+	"strconv"
 
-// Round 四舍五入
+	"github.com/ahKevinXy/go-web-tools/common/decimal"
+)
+
+// Round 四舍五入，保留n位小数
+// 按照十进制舍入，0.5远离0进位，1.005保留2位得到1.01，-1.5保留0位得到-2
+// n小于0时舍入到整十、整百等，例如Round(1250, -2)得到1300
+// NaN和无穷大原样返回；金额计算请直接使用decimal.Decimal
 func Round(f float64, n int) float64 {
-	pow10_n := math.Pow10(n)
-	return math.Trunc((f+0.5/pow10_n)*pow10_n) / pow10_n
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	d := decimal.NewFromFloat(f)
+	if n >= 0 {
+		if n >= int(d.Scale()) {
+			return f
+		}
+		return d.Round(int32(n), decimal.RoundHalfUp).Float64()
+	}
+	if n < -308 {
+		// 最大的float64也不到10^-n的一半
+		return 0
+	}
+	unit := decimal.MustParse("1e" + strconv.Itoa(-n))
+	return d.DivRound(unit, 0, decimal.RoundHalfUp).Mul(unit).Float64()
 }
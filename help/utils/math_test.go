@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		f    float64
+		n    int
+		want float64
+	}{
+		{1.005, 2, 1.01},
+		{1.004, 2, 1},
+		{2.5, 0, 3},
+		{-1.005, 2, -1.01},
+		{-1.5, 0, -2},
+		{-1.2, 0, -1},
+		{-1.4, 0, -1},
+		{0.1, 5, 0.1},
+		{1250, -2, 1300},
+		{1249, -2, 1200},
+		{-1250, -2, -1300},
+		{-1249, -2, -1200},
+		{15, -1, 20},
+		{-15, -1, -20},
+		{1e300, -400, 0},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.f, tt.n); got != tt.want {
+			t.Errorf("Round(%v, %v) = %v, want %v", tt.f, tt.n, got, tt.want)
+		}
+	}
+
+	if got := Round(math.Inf(-1), 2); !math.IsInf(got, -1) {
+		t.Errorf("Round(-Inf, 2) = %v, want -Inf", got)
+	}
+	if got := Round(math.NaN(), 2); !math.IsNaN(got) {
+		t.Errorf("Round(NaN, 2) = %v, want NaN", got)
+	}
+}